@@ -96,6 +96,78 @@ func (ce *CallExpression) String() string {
 	return out.String()
 }
 
+// ConditionalExpression is `a if cond else b`.
+type ConditionalExpression struct {
+	Token       token.Token // The 'if' token
+	Consequence Expression
+	Condition   Expression
+	Alternative Expression
+}
+
+func (ce *ConditionalExpression) expressionNode()      {}
+func (ce *ConditionalExpression) TokenLiteral() string { return ce.Token.Literal }
+func (ce *ConditionalExpression) String() string {
+	return fmt.Sprintf("(%s if %s else %s)",
+		ce.Consequence.String(), ce.Condition.String(), ce.Alternative.String())
+}
+
+// ChainedComparison is `a < b <= c`, which compares each operand with the
+// next and evaluates every operand at most once.
+type ChainedComparison struct {
+	Token     token.Token // The first operator token
+	Operands  []Expression
+	Operators []string // Operators[i] sits between Operands[i] and Operands[i+1]
+}
+
+func (cc *ChainedComparison) expressionNode()      {}
+func (cc *ChainedComparison) TokenLiteral() string { return cc.Token.Literal }
+func (cc *ChainedComparison) String() string {
+	var out bytes.Buffer
+	out.WriteString("(")
+	out.WriteString(cc.Operands[0].String())
+	for i, op := range cc.Operators {
+		out.WriteString(" " + op + " ")
+		out.WriteString(cc.Operands[i+1].String())
+	}
+	out.WriteString(")")
+	return out.String()
+}
+
+// KeywordArgument is `name=value` in the argument list of a call.
+type KeywordArgument struct {
+	Token token.Token // The name token
+	Name  *Identifier
+	Value Expression
+}
+
+func (ka *KeywordArgument) expressionNode()      {}
+func (ka *KeywordArgument) TokenLiteral() string { return ka.Token.Literal }
+func (ka *KeywordArgument) String() string {
+	return ka.Name.String() + "=" + ka.Value.String()
+}
+
+// PipelineExpression feeds Left as the first argument of Right: `x |> f(y)`
+// is `f(x, y)` and `x |> f` is `f(x)`.
+type PipelineExpression struct {
+	Token token.Token // The '|>' token
+	Left  Expression
+	Right Expression
+}
+
+func (pe *PipelineExpression) expressionNode()      {}
+func (pe *PipelineExpression) TokenLiteral() string { return pe.Token.Literal }
+func (pe *PipelineExpression) String() string {
+	return fmt.Sprintf("(%s |> %s)", pe.Left.String(), pe.Right.String())
+}
+
+type NoneLiteral struct {
+	Token token.Token // The 'none' token
+}
+
+func (nl *NoneLiteral) expressionNode()      {}
+func (nl *NoneLiteral) TokenLiteral() string { return nl.Token.Literal }
+func (nl *NoneLiteral) String() string       { return "none" }
+
 type Boolean struct {
 	Token token.Token
 	Value bool
@@ -178,9 +250,56 @@ func (ie *IndexExpression) String() string {
 	return out.String()
 }
 
+// MemberExpression is `object.member`, which looks up a method of a builtin
+// value or of an instance.
+type MemberExpression struct {
+	Token  token.Token // The . token
+	Object Expression
+	Member *Identifier
+}
+
+func (me *MemberExpression) expressionNode()      {}
+func (me *MemberExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MemberExpression) String() string {
+	return me.Object.String() + "." + me.Member.String()
+}
+
+// SliceExpression is `left[start:stop:step]`; any of the bounds may be nil.
+type SliceExpression struct {
+	Token token.Token // The [ token
+	Left  Expression
+	Start Expression
+	Stop  Expression
+	Step  Expression
+}
+
+func (se *SliceExpression) expressionNode()      {}
+func (se *SliceExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SliceExpression) String() string {
+	var out bytes.Buffer
+	bound := func(exp Expression) {
+		if exp != nil {
+			out.WriteString(exp.String())
+		}
+	}
+	out.WriteString("(")
+	out.WriteString(se.Left.String())
+	out.WriteString("[")
+	bound(se.Start)
+	out.WriteString(":")
+	bound(se.Stop)
+	if se.Step != nil {
+		out.WriteString(":")
+		bound(se.Step)
+	}
+	out.WriteString("])")
+	return out.String()
+}
+
 type HashLiteral struct {
 	Token token.Token
 	Pairs map[Expression]Expression
+	Keys  []Expression // Keys of Pairs in source order
 }
 
 func (hl *HashLiteral) expressionNode()      {}
@@ -189,8 +308,8 @@ func (hl *HashLiteral) String() string {
 	var out bytes.Buffer
 
 	pairs := []string{}
-	for key, value := range hl.Pairs {
-		pairs = append(pairs, key.String()+":"+value.String())
+	for _, key := range hl.Keys {
+		pairs = append(pairs, key.String()+":"+hl.Pairs[key].String())
 	}
 	out.WriteString("{")
 	out.WriteString(strings.Join(pairs, ", "))
@@ -198,6 +317,21 @@ func (hl *HashLiteral) String() string {
 	return out.String()
 }
 
+type SetLiteral struct {
+	Token    token.Token // The '{' token
+	Elements []Expression
+}
+
+func (sl *SetLiteral) expressionNode()      {}
+func (sl *SetLiteral) TokenLiteral() string { return sl.Token.Literal }
+func (sl *SetLiteral) String() string {
+	elements := []string{}
+	for _, el := range sl.Elements {
+		elements = append(elements, el.String())
+	}
+	return "{" + strings.Join(elements, ", ") + "}"
+}
+
 type TupleLiteral struct {
 	Token    token.Token  // The '(' token
 	Elements []Expression // Elements in the tuple
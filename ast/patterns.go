@@ -0,0 +1,111 @@
+package ast
+
+import (
+	"strings"
+
+	"thecarrionlanguage/token"
+)
+
+// Pattern is the left-hand side of a `case` arm in a match statement.
+type Pattern interface {
+	Node
+	patternNode()
+}
+
+// LiteralPattern matches a value equal to a number, string or boolean literal.
+type LiteralPattern struct {
+	Token token.Token
+	Value Expression
+}
+
+func (lp *LiteralPattern) patternNode()         {}
+func (lp *LiteralPattern) TokenLiteral() string { return lp.Token.Literal }
+func (lp *LiteralPattern) String() string       { return lp.Value.String() }
+
+// CapturePattern matches any value and binds it to Name.
+type CapturePattern struct {
+	Token token.Token
+	Name  *Identifier
+}
+
+func (cp *CapturePattern) patternNode()         {}
+func (cp *CapturePattern) TokenLiteral() string { return cp.Token.Literal }
+func (cp *CapturePattern) String() string       { return cp.Name.String() }
+
+// WildcardPattern matches any value without binding it.
+type WildcardPattern struct {
+	Token token.Token // The '_' token
+}
+
+func (wp *WildcardPattern) patternNode()         {}
+func (wp *WildcardPattern) TokenLiteral() string { return wp.Token.Literal }
+func (wp *WildcardPattern) String() string       { return "_" }
+
+// StarPattern collects the remaining elements of a sequence pattern.
+// A nil Name discards them (`*_`).
+type StarPattern struct {
+	Token token.Token // The '*' token
+	Name  *Identifier
+}
+
+func (sp *StarPattern) patternNode()         {}
+func (sp *StarPattern) TokenLiteral() string { return sp.Token.Literal }
+func (sp *StarPattern) String() string {
+	if sp.Name == nil {
+		return "*_"
+	}
+	return "*" + sp.Name.String()
+}
+
+// SequencePattern matches an array or tuple element by element.
+type SequencePattern struct {
+	Token    token.Token // The '[' or '(' token
+	Elements []Pattern
+}
+
+func (sp *SequencePattern) patternNode()         {}
+func (sp *SequencePattern) TokenLiteral() string { return sp.Token.Literal }
+func (sp *SequencePattern) String() string {
+	elems := []string{}
+	for _, e := range sp.Elements {
+		elems = append(elems, e.String())
+	}
+	if sp.Token.Type == token.LPAREN {
+		return "(" + strings.Join(elems, ", ") + ")"
+	}
+	return "[" + strings.Join(elems, ", ") + "]"
+}
+
+// HashPattern matches a hash containing every listed key; extra keys are allowed.
+type HashPattern struct {
+	Token  token.Token // The '{' token
+	Keys   []Expression
+	Values []Pattern
+}
+
+func (hp *HashPattern) patternNode()         {}
+func (hp *HashPattern) TokenLiteral() string { return hp.Token.Literal }
+func (hp *HashPattern) String() string {
+	pairs := []string{}
+	for i, key := range hp.Keys {
+		pairs = append(pairs, key.String()+":"+hp.Values[i].String())
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+// ClassPattern matches values of the type named by Type, e.g. `int(n)`.
+type ClassPattern struct {
+	Token     token.Token
+	Type      *Identifier
+	Arguments []Pattern
+}
+
+func (cp *ClassPattern) patternNode()         {}
+func (cp *ClassPattern) TokenLiteral() string { return cp.Token.Literal }
+func (cp *ClassPattern) String() string {
+	args := []string{}
+	for _, a := range cp.Arguments {
+		args = append(args, a.String())
+	}
+	return cp.Type.String() + "(" + strings.Join(args, ", ") + ")"
+}
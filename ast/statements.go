@@ -19,8 +19,12 @@ func (as *AssignStatement) TokenLiteral() string { return as.Token.Literal }
 
 func (as *AssignStatement) String() string {
 	var out bytes.Buffer
+	operator := as.Operator
+	if operator == "" {
+		operator = "="
+	}
 	out.WriteString(as.Name.String())
-	out.WriteString(" = ")
+	out.WriteString(" " + operator + " ")
 	if as.Value != nil {
 		out.WriteString(as.Value.String())
 	}
@@ -46,6 +50,131 @@ func (rs *ReturnStatement) String() string {
 	return out.String()
 }
 
+type YieldStatement struct {
+	Token token.Token // The 'yield' token
+	Value Expression
+}
+
+func (ys *YieldStatement) statementNode()       {}
+func (ys *YieldStatement) TokenLiteral() string { return ys.Token.Literal }
+func (ys *YieldStatement) String() string {
+	var out strings.Builder
+
+	out.WriteString(ys.TokenLiteral())
+
+	if ys.Value != nil {
+		out.WriteString(" " + ys.Value.String())
+	}
+
+	return out.String()
+}
+
+// VarStatement declares a name in the current scope: `var x = 1` or
+// `const LIMIT = 10`. A var without a value starts as none.
+type VarStatement struct {
+	Token token.Token // The 'var' or 'const' token
+	Name  *Identifier
+	Value Expression
+}
+
+func (vs *VarStatement) statementNode()       {}
+func (vs *VarStatement) TokenLiteral() string { return vs.Token.Literal }
+func (vs *VarStatement) String() string {
+	var out strings.Builder
+
+	out.WriteString(vs.TokenLiteral() + " " + vs.Name.String())
+	if vs.Value != nil {
+		out.WriteString(" = " + vs.Value.String())
+	}
+
+	return out.String()
+}
+
+// ScopeStatement is `global a, b` or `nonlocal a, b`, which make
+// assignments to those names inside a spell rebind an outer variable.
+type ScopeStatement struct {
+	Token token.Token // The 'global' or 'nonlocal' token
+	Names []*Identifier
+}
+
+func (ss *ScopeStatement) statementNode()       {}
+func (ss *ScopeStatement) TokenLiteral() string { return ss.Token.Literal }
+func (ss *ScopeStatement) String() string {
+	names := []string{}
+	for _, name := range ss.Names {
+		names = append(names, name.String())
+	}
+	return ss.TokenLiteral() + " " + strings.Join(names, ", ")
+}
+
+// WithStatement runs Body between the enter and exit hooks of Context,
+// binding the value returned by the enter hook to Name when one is given.
+type WithStatement struct {
+	Token   token.Token // The 'with' token
+	Context Expression
+	Name    *Identifier // Optional
+	Body    *BlockStatement
+}
+
+func (ws *WithStatement) statementNode()       {}
+func (ws *WithStatement) TokenLiteral() string { return ws.Token.Literal }
+func (ws *WithStatement) String() string {
+	var out strings.Builder
+
+	out.WriteString("with " + ws.Context.String())
+	if ws.Name != nil {
+		out.WriteString(" as " + ws.Name.String())
+	}
+	out.WriteString(":\n")
+	out.WriteString(ws.Body.String())
+
+	return out.String()
+}
+
+// ImportStatement is `import name` or `import name as alias`.
+type ImportStatement struct {
+	Token token.Token // The 'import' token
+	Name  *Identifier
+	Alias *Identifier // Optional
+}
+
+func (is *ImportStatement) statementNode()       {}
+func (is *ImportStatement) TokenLiteral() string { return is.Token.Literal }
+func (is *ImportStatement) String() string {
+	if is.Alias != nil {
+		return "import " + is.Name.String() + " as " + is.Alias.String()
+	}
+	return "import " + is.Name.String()
+}
+
+// IgnoreStatement is a no-op, for blocks that must not be empty.
+type IgnoreStatement struct {
+	Token token.Token // The 'ignore' token
+}
+
+func (is *IgnoreStatement) statementNode()       {}
+func (is *IgnoreStatement) TokenLiteral() string { return is.Token.Literal }
+func (is *IgnoreStatement) String() string       { return is.Token.Literal }
+
+type AssertStatement struct {
+	Token     token.Token // The 'assert' token
+	Condition Expression
+	Message   Expression // Optional
+}
+
+func (as *AssertStatement) statementNode()       {}
+func (as *AssertStatement) TokenLiteral() string { return as.Token.Literal }
+func (as *AssertStatement) String() string {
+	var out strings.Builder
+
+	out.WriteString(as.TokenLiteral() + " " + as.Condition.String())
+	if as.Message != nil {
+		out.WriteString(", " + as.Message.String())
+	}
+
+	return out.String()
+}
+
 type BlockStatement struct {
 	Token      token.Token
 	Statements []Statement
@@ -143,6 +272,7 @@ type FunctionDefinition struct {
 	Name       *Identifier
 	Parameters []*Identifier
 	Body       *BlockStatement
+	Decorators []Expression // Applied bottom-up before the spell is bound
 }
 
 func (fd *FunctionDefinition) statementNode()       {}
@@ -155,6 +285,9 @@ func (fd *FunctionDefinition) String() string {
 		params = append(params, p.String())
 	}
 
+	for _, d := range fd.Decorators {
+		out.WriteString("@" + d.String() + "\n")
+	}
 	out.WriteString(fd.TokenLiteral() + " ")
 	out.WriteString(fd.Name.String())
 	out.WriteString("(")
@@ -164,3 +297,47 @@ func (fd *FunctionDefinition) String() string {
 
 	return out.String()
 }
+
+type MatchStatement struct {
+	Token   token.Token // The 'match' token
+	Subject Expression
+	Cases   []*CaseClause
+}
+
+func (ms *MatchStatement) statementNode()       {}
+func (ms *MatchStatement) TokenLiteral() string { return ms.Token.Literal }
+func (ms *MatchStatement) String() string {
+	var out strings.Builder
+
+	out.WriteString("match ")
+	out.WriteString(ms.Subject.String())
+	out.WriteString(":\n")
+	for _, c := range ms.Cases {
+		out.WriteString(c.String())
+	}
+
+	return out.String()
+}
+
+type CaseClause struct {
+	Token   token.Token // The 'case' token
+	Pattern Pattern
+	Guard   Expression
+	Body    *BlockStatement
+}
+
+func (cc *CaseClause) TokenLiteral() string { return cc.Token.Literal }
+func (cc *CaseClause) String() string {
+	var out strings.Builder
+
+	out.WriteString("case ")
+	out.WriteString(cc.Pattern.String())
+	if cc.Guard != nil {
+		out.WriteString(" if ")
+		out.WriteString(cc.Guard.String())
+	}
+	out.WriteString(":\n")
+	out.WriteString(cc.Body.String())
+
+	return out.String()
+}
@@ -1,12 +1,18 @@
 package evaluator
 
 import (
-	"fmt"
 	"strconv"
+	"unicode/utf8"
 
 	"thecarrionlanguage/object"
 )
 
+func init() {
+	for name, builtin := range builtins {
+		builtin.Name = name
+	}
+}
+
 var builtins = map[string]*object.Builtin{
 	"len": {
 		Fn: func(args ...object.Object) object.Object {
@@ -14,23 +20,35 @@ var builtins = map[string]*object.Builtin{
 				return newError("wrong number of arguments. got=%d, want=1",
 					len(args))
 			}
+			if result, ok := callHook(args[0], "__len__"); ok {
+				if !isError(result) && result.Type() != object.INTEGER_OBJ {
+					return newError("__len__ returned %s, want INTEGER", result.Type())
+				}
+				return result
+			}
 			switch arg := args[0].(type) {
 			case *object.String:
-				return &object.Integer{Value: int64(len(arg.Value))}
+				return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
+			case *object.Array:
+				return &object.Integer{Value: int64(len(arg.Elements))}
+			case *object.Tuple:
+				return &object.Integer{Value: int64(len(arg.Elements))}
+			case *object.Hash:
+				return &object.Integer{Value: int64(len(arg.Pairs))}
+			case *object.Set:
+				return &object.Integer{Value: int64(len(arg.Keys))}
+			case *object.Range:
+				return &object.Integer{Value: arg.Len()}
 			default:
 				return newError("argument to `len` not supported, got %s",
 					args[0].Type())
 			}
 		},
 	},
-	"print": {
-		Fn: func(args ...object.Object) object.Object {
-			for _, arg := range args {
-				fmt.Print(arg.Inspect(), " ")
-			}
-			return &object.None{}
-		},
-	},
+	"open":       {Fn: openBuiltin},
+	"read_file":  {Fn: readFileBuiltin},
+	"write_file": {Fn: writeFileBuiltin},
+	"lock":       {Fn: lockBuiltin},
 
 	"type": {
 		Fn: func(args ...object.Object) object.Object {
@@ -89,7 +107,7 @@ var builtins = map[string]*object.Builtin{
 			if len(args) != 1 {
 				return newError("wrong number of arguments. got=%d, want=1", len(args))
 			}
-			return &object.String{Value: args[0].Inspect()}
+			return toString(args[0])
 		},
 	},
 	"list": {
@@ -97,18 +115,14 @@ var builtins = map[string]*object.Builtin{
 			if len(args) != 1 {
 				return newError("wrong number of arguments. got=%d, want=1", len(args))
 			}
-			switch arg := args[0].(type) {
-			case *object.String:
-				elements := make([]object.Object, len(arg.Value))
-				for i, char := range arg.Value {
-					elements[i] = &object.String{Value: string(char)}
-				}
-				return &object.Array{Elements: elements}
-			case *object.Tuple:
-				return &object.Array{Elements: arg.Elements}
-			default:
-				return newError("cannot convert %s to list", arg.Type())
+			if !isIterable(args[0]) {
+				return newError("cannot convert %s to list", args[0].Type())
 			}
+			elements, err := collect(args[0])
+			if err != nil {
+				return err
+			}
+			return &object.Array{Elements: elements}
 		},
 	},
 	"tuple": {
@@ -116,14 +130,84 @@ var builtins = map[string]*object.Builtin{
 			if len(args) != 1 {
 				return newError("wrong number of arguments. got=%d, want=1", len(args))
 			}
-			switch arg := args[0].(type) {
-			case *object.Array:
-				return &object.Tuple{Elements: arg.Elements}
-			case *object.Tuple:
-				return arg
-			default:
-				return newError("cannot convert %s to tuple", arg.Type())
+			if tuple, ok := args[0].(*object.Tuple); ok {
+				return tuple
 			}
+			if !isIterable(args[0]) {
+				return newError("cannot convert %s to tuple", args[0].Type())
+			}
+			elements, err := collect(args[0])
+			if err != nil {
+				return err
+			}
+			return &object.Tuple{Elements: elements}
+		},
+	},
+	"keys":      {Fn: hashView("keys", func(pair object.HashPair) object.Object { return pair.Key })},
+	"values":    {Fn: hashView("values", func(pair object.HashPair) object.Object { return pair.Value })},
+	"append":    {Fn: appendBuiltin("append")},
+	"push":      {Fn: appendBuiltin("push")},
+	"pop":       {Fn: popBuiltin},
+	"insert":    {Fn: insertBuiltin},
+	"remove":    {Fn: removeBuiltin},
+	"index":     {Fn: indexBuiltin},
+	"count":     {Fn: countBuiltin},
+	"reverse":   {Fn: reverseBuiltin},
+	"sorted":    {KwFn: sortedBuiltin},
+	"min":       {Fn: extremeBuiltin("min", -1)},
+	"max":       {Fn: extremeBuiltin("max", 1)},
+	"sum":       {Fn: sumBuiltin},
+	"any":       {Fn: truthBuiltin("any", true)},
+	"all":       {Fn: truthBuiltin("all", false)},
+	"enumerate": {Fn: enumerateBuiltin},
+	"zip":       {Fn: zipBuiltin},
+	"range":     {Fn: rangeBuiltin},
+	"dir":       {Fn: dirBuiltin},
+	"map":       {Fn: mapBuiltin, PipeArg: 1},
+	"filter":    {Fn: filterBuiltin, PipeArg: 1},
+	"reduce":    {Fn: reduceBuiltin, PipeArg: 1},
+	"partial":   {Fn: partialBuiltin},
+	"compose":   {Fn: composeBuiltin},
+	"memoize":   {Fn: memoizeBuiltin},
+	"set": {
+		Fn: func(args ...object.Object) object.Object {
+			return buildSet("set", args, false)
+		},
+	},
+	"frozenset": {
+		Fn: func(args ...object.Object) object.Object {
+			return buildSet("frozenset", args, true)
 		},
 	},
 }
+
+// buildSet implements set() and frozenset(), which take an optional
+// iterable of elements.
+func buildSet(name string, args []object.Object, frozen bool) object.Object {
+	if len(args) > 1 {
+		return newError("wrong number of arguments. got=%d, want=0 or 1", len(args))
+	}
+	if len(args) == 0 {
+		return newSet(nil, frozen)
+	}
+	if !isIterable(args[0]) {
+		return newError("cannot convert %s to %s", args[0].Type(), name)
+	}
+	elements, err := collect(args[0])
+	if err != nil {
+		return err
+	}
+	return newSet(elements, frozen)
+}
+
+// toString converts obj for string() and print(), letting instances
+// override the conversion with __str__.
+func toString(obj object.Object) object.Object {
+	if result, ok := callHook(obj, "__str__"); ok {
+		if !isError(result) && result.Type() != object.STRING_OBJ {
+			return newError("__str__ returned %s, want STRING", result.Type())
+		}
+		return result
+	}
+	return &object.String{Value: obj.Inspect()}
+}
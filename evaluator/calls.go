@@ -0,0 +1,93 @@
+package evaluator
+
+import (
+	"sort"
+
+	"thecarrionlanguage/ast"
+	"thecarrionlanguage/object"
+)
+
+// evalCallArguments evaluates the arguments of a call, separating keyword
+// arguments from positional ones.
+func evalCallArguments(
+	exps []ast.Expression,
+	env *object.Environment,
+) ([]object.Object, map[string]object.Object, object.Object) {
+	args := []object.Object{}
+	var kwargs map[string]object.Object
+	for _, e := range exps {
+		keyword, ok := e.(*ast.KeywordArgument)
+		if !ok {
+			evaluated := Eval(e, env)
+			if isError(evaluated) {
+				return nil, nil, evaluated
+			}
+			args = append(args, evaluated)
+			continue
+		}
+		if _, repeated := kwargs[keyword.Name.Value]; repeated {
+			return nil, nil, newError("keyword argument repeated: %s", keyword.Name.Value)
+		}
+		evaluated := Eval(keyword.Value, env)
+		if isError(evaluated) {
+			return nil, nil, evaluated
+		}
+		if kwargs == nil {
+			kwargs = map[string]object.Object{}
+		}
+		kwargs[keyword.Name.Value] = evaluated
+	}
+	return args, kwargs, nil
+}
+
+// bindKeywordArguments places keyword arguments at the positions of the
+// spell parameters they name, after the positional arguments.
+func bindKeywordArguments(
+	fn *object.Function,
+	args []object.Object,
+	kwargs map[string]object.Object,
+) ([]object.Object, *object.Error) {
+	if len(args) > len(fn.Parameters) {
+		return nil, newError("wrong number of arguments. got=%d, want=%d",
+			len(args)+len(kwargs), len(fn.Parameters))
+	}
+	bound := make([]object.Object, len(fn.Parameters))
+	copy(bound, args)
+	used := 0
+	for i, param := range fn.Parameters {
+		value, ok := kwargs[param.Value]
+		if !ok {
+			continue
+		}
+		if i < len(args) {
+			return nil, newError("multiple values for argument %s", param.Value)
+		}
+		bound[i] = value
+		used++
+	}
+	if used < len(kwargs) {
+		names := make([]string, 0, len(kwargs))
+		for name := range kwargs {
+			if !hasParameter(fn, name) {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		return nil, newError("unexpected keyword argument %s", names[0])
+	}
+	for i, value := range bound {
+		if value == nil {
+			return nil, newError("missing argument %s", fn.Parameters[i].Value)
+		}
+	}
+	return bound, nil
+}
+
+func hasParameter(fn *object.Function, name string) bool {
+	for _, param := range fn.Parameters {
+		if param.Value == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,283 @@
+package evaluator
+
+import (
+	"cmp"
+	"strings"
+
+	"thecarrionlanguage/ast"
+	"thecarrionlanguage/object"
+)
+
+// objectPair identifies two containers whose comparison is in progress.
+type objectPair struct {
+	left, right object.Object
+}
+
+// valuesEqual reports whether two objects hold the same value. Numbers
+// compare across int and float, collections compare element by element and
+// anything else falls back to identity.
+func valuesEqual(left, right object.Object) bool {
+	return deepEqual(left, right, nil)
+}
+
+func deepEqual(left, right object.Object, seen map[objectPair]bool) bool {
+	switch left := left.(type) {
+	case *object.Integer:
+		switch right := right.(type) {
+		case *object.Integer:
+			return left.Value == right.Value
+		case *object.Float:
+			return float64(left.Value) == right.Value
+		}
+		return false
+	case *object.Float:
+		switch right := right.(type) {
+		case *object.Integer:
+			return left.Value == float64(right.Value)
+		case *object.Float:
+			return left.Value == right.Value
+		}
+		return false
+	case *object.String:
+		right, ok := right.(*object.String)
+		return ok && left.Value == right.Value
+	case *object.Boolean:
+		right, ok := right.(*object.Boolean)
+		return ok && left.Value == right.Value
+	case *object.None:
+		_, ok := right.(*object.None)
+		return ok
+	case *object.Array:
+		right, ok := right.(*object.Array)
+		return ok && sequencesEqual(left, right, left.Elements, right.Elements, seen)
+	case *object.Tuple:
+		right, ok := right.(*object.Tuple)
+		return ok && sequencesEqual(left, right, left.Elements, right.Elements, seen)
+	case *object.Hash:
+		right, ok := right.(*object.Hash)
+		if !ok || len(left.Pairs) != len(right.Pairs) {
+			return false
+		}
+		seen, fresh := enterComparison(seen, left, right)
+		if !fresh {
+			return true
+		}
+		for key, pair := range left.Pairs {
+			other, ok := right.Pairs[key]
+			if !ok || !deepEqual(pair.Value, other.Value, seen) {
+				return false
+			}
+		}
+		return true
+	case *object.Set:
+		right, ok := right.(*object.Set)
+		return ok && len(left.Keys) == len(right.Keys) && isSubset(left, right)
+	case *object.Range:
+		right, ok := right.(*object.Range)
+		return ok && rangesEqual(left, right)
+	}
+	return left == right
+}
+
+func sequencesEqual(left, right object.Object, a, b []object.Object, seen map[objectPair]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen, fresh := enterComparison(seen, left, right)
+	if !fresh {
+		return true
+	}
+	for i := range a {
+		if !deepEqual(a[i], b[i], seen) {
+			return false
+		}
+	}
+	return true
+}
+
+// enterComparison records that left and right are being compared. It
+// reports false when the pair is already being compared further up, which
+// only happens for self-referencing structures; the caller then treats the
+// pair as equal so that the rest of the structure decides the result.
+func enterComparison(seen map[objectPair]bool, left, right object.Object) (map[objectPair]bool, bool) {
+	if left == right {
+		return seen, false
+	}
+	if seen == nil {
+		seen = map[objectPair]bool{}
+	}
+	pair := objectPair{left, right}
+	if seen[pair] {
+		return seen, false
+	}
+	seen[pair] = true
+	return seen, true
+}
+
+// compareValues orders two objects, returning -1, 0 or 1. Strings, arrays
+// and tuples are ordered lexicographically; an error names the first pair of
+// values that cannot be ordered by operator.
+func compareValues(operator string, left, right object.Object, seen map[objectPair]bool) (int, *object.Error) {
+	if isNumeric(left) && isNumeric(right) {
+		leftInt, leftOk := left.(*object.Integer)
+		rightInt, rightOk := right.(*object.Integer)
+		if leftOk && rightOk {
+			return cmp.Compare(leftInt.Value, rightInt.Value), nil
+		}
+		return cmp.Compare(toFloat(left), toFloat(right)), nil
+	}
+	switch left := left.(type) {
+	case *object.String:
+		if right, ok := right.(*object.String); ok {
+			return strings.Compare(left.Value, right.Value), nil
+		}
+	case *object.Array:
+		if right, ok := right.(*object.Array); ok {
+			return compareSequences(operator, left, right, left.Elements, right.Elements, seen)
+		}
+	case *object.Tuple:
+		if right, ok := right.(*object.Tuple); ok {
+			return compareSequences(operator, left, right, left.Elements, right.Elements, seen)
+		}
+	}
+	if left.Type() != right.Type() {
+		return 0, newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
+	}
+	return 0, newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+}
+
+func compareSequences(
+	operator string,
+	left, right object.Object,
+	a, b []object.Object,
+	seen map[objectPair]bool,
+) (int, *object.Error) {
+	seen, fresh := enterComparison(seen, left, right)
+	if !fresh {
+		return 0, nil
+	}
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if deepEqual(a[i], b[i], seen) {
+			continue
+		}
+		return compareValues(operator, a[i], b[i], seen)
+	}
+	return cmp.Compare(len(a), len(b)), nil
+}
+
+// evalOrderingExpression implements <, >, <= and >=.
+func evalOrderingExpression(operator string, left, right object.Object) object.Object {
+	result, err := compareValues(operator, left, right, nil)
+	if err != nil {
+		return err
+	}
+	switch operator {
+	case "<":
+		return nativeBoolToBooleanObject(result < 0)
+	case ">":
+		return nativeBoolToBooleanObject(result > 0)
+	case "<=":
+		return nativeBoolToBooleanObject(result <= 0)
+	default:
+		return nativeBoolToBooleanObject(result >= 0)
+	}
+}
+
+// evalChainedComparison evaluates `a < b <= c` as `a < b and b <= c`,
+// evaluating each operand once and stopping at the first false comparison.
+func evalChainedComparison(node *ast.ChainedComparison, env *object.Environment) object.Object {
+	left := Eval(node.Operands[0], env)
+	if isError(left) {
+		return left
+	}
+	for i, operator := range node.Operators {
+		right := Eval(node.Operands[i+1], env)
+		if isError(right) {
+			return right
+		}
+		result := evalInfixExpression(operator, left, right)
+		if isError(result) {
+			return result
+		}
+		truthy, err := isTruthy(result)
+		if err != nil {
+			return err
+		}
+		if !truthy {
+			return result
+		}
+		left = right
+	}
+	return TRUE
+}
+
+// isIdentical implements `is`. Immutable scalars have no observable
+// identity, so they are identical when their type and value agree;
+// everything else must be the very same object.
+func isIdentical(left, right object.Object) bool {
+	switch left.(type) {
+	case *object.Integer, *object.Float, *object.String, *object.Boolean, *object.None:
+		return left.Type() == right.Type() && valuesEqual(left, right)
+	}
+	return left == right
+}
+
+// evalMembership implements `in`, returning TRUE, FALSE or an error.
+func evalMembership(needle, container object.Object) object.Object {
+	switch container := container.(type) {
+	case *object.String:
+		sub, ok := needle.(*object.String)
+		if !ok {
+			return newError("'in <string>' requires string as left operand, got %s", needle.Type())
+		}
+		return nativeBoolToBooleanObject(strings.Contains(container.Value, sub.Value))
+	case *object.Hash:
+		key, ok := object.HashKeyOf(needle)
+		if !ok {
+			return newError("unusable as hash key: %s", needle.Type())
+		}
+		_, ok = container.Pairs[key]
+		return nativeBoolToBooleanObject(ok)
+	case *object.Set:
+		key, ok := object.HashKeyOf(needle)
+		if !ok {
+			return newError("unusable as set element: %s", needle.Type())
+		}
+		return nativeBoolToBooleanObject(container.Contains(key))
+	case *object.Array:
+		return nativeBoolToBooleanObject(containsValue(container.Elements, needle))
+	case *object.Tuple:
+		return nativeBoolToBooleanObject(containsValue(container.Elements, needle))
+	case *object.Range:
+		return nativeBoolToBooleanObject(rangeContains(container, needle))
+	}
+
+	iterator, errObj := iterate(container)
+	if errObj != nil {
+		return newError("argument of type %s is not iterable", container.Type())
+	}
+	for {
+		item, ok := iterator.Next()
+		if !ok {
+			return FALSE
+		}
+		if isError(item) {
+			return item
+		}
+		if valuesEqual(item, needle) {
+			if gen, ok := iterator.(*object.Generator); ok {
+				gen.Stop()
+			}
+			return TRUE
+		}
+	}
+}
+
+func containsValue(elements []object.Object, needle object.Object) bool {
+	for _, el := range elements {
+		if valuesEqual(el, needle) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package evaluator
+
+import (
+	"thecarrionlanguage/ast"
+	"thecarrionlanguage/object"
+)
+
+// evalWithStatement runs the body between the context's enter and exit
+// hooks. The exit hook runs however the body ends, including by return or
+// error, and may suppress the error.
+func evalWithStatement(node *ast.WithStatement, env *object.Environment) object.Object {
+	ctx := Eval(node.Context, env)
+	if isError(ctx) {
+		return ctx
+	}
+	value, err := enterContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	var result object.Object
+	if node.Name != nil {
+		if err := assign(env, node.Name.Value, value); err != nil {
+			result = err
+		}
+	}
+	if result == nil {
+		result = Eval(node.Body, env)
+	}
+
+	bodyErr, _ := result.(*object.Error)
+	suppress, err := exitContext(ctx, bodyErr)
+	if err != nil {
+		return err
+	}
+	if bodyErr != nil && suppress {
+		return NONE
+	}
+	return result
+}
+
+// enterContext calls the enter hook of a builtin context manager or of an
+// instance defining both __enter__ and __exit__.
+func enterContext(ctx object.Object) (object.Object, *object.Error) {
+	if manager, ok := ctx.(object.ContextManager); ok {
+		value := manager.Enter()
+		if err, ok := value.(*object.Error); ok {
+			return nil, err
+		}
+		return value, nil
+	}
+	if instance, ok := ctx.(object.Instance); ok {
+		_, hasEnter := instance.Method("__enter__")
+		_, hasExit := instance.Method("__exit__")
+		if hasEnter && hasExit {
+			value, _ := callHook(ctx, "__enter__")
+			if err, ok := value.(*object.Error); ok {
+				return nil, err
+			}
+			return value, nil
+		}
+	}
+	return nil, newError("%s does not support the with statement", ctx.Type())
+}
+
+// exitContext calls the exit hook with the error that ended the body, or
+// none, and reports whether that error is suppressed.
+func exitContext(ctx object.Object, bodyErr *object.Error) (bool, *object.Error) {
+	if manager, ok := ctx.(object.ContextManager); ok {
+		return manager.Exit(bodyErr)
+	}
+
+	var arg object.Object = NONE
+	if bodyErr != nil {
+		arg = bodyErr
+	}
+	result, _ := callHook(ctx, "__exit__", arg)
+	if err, ok := result.(*object.Error); ok {
+		return false, err
+	}
+	return isTruthy(result)
+}
+
+func lockBuiltin(args ...object.Object) object.Object {
+	if len(args) != 0 {
+		return newError("wrong number of arguments. got=%d, want=0", len(args))
+	}
+	return &object.Lock{}
+}
+
+func lockAcquire(receiver object.Object, args ...object.Object) object.Object {
+	if err := receiver.(*object.Lock).Acquire(); err != nil {
+		return newError("%s", err)
+	}
+	return NONE
+}
+
+func lockRelease(receiver object.Object, args ...object.Object) object.Object {
+	if err := receiver.(*object.Lock).Release(); err != nil {
+		return newError("%s", err)
+	}
+	return NONE
+}
+
+func lockLocked(receiver object.Object, args ...object.Object) object.Object {
+	return nativeBoolToBooleanObject(receiver.(*object.Lock).Locked())
+}
@@ -2,6 +2,8 @@ package evaluator
 
 import (
 	"fmt"
+	"math"
+	"strings"
 
 	"thecarrionlanguage/ast"
 	"thecarrionlanguage/object"
@@ -24,6 +26,20 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return evalBlockStatement(node, env)
 	case *ast.IfStatement:
 		return evalIfExpression(node, env)
+	case *ast.MatchStatement:
+		return evalMatchStatement(node, env)
+	case *ast.ForStatement:
+		return evalForStatement(node, env)
+	case *ast.YieldStatement:
+		return evalYieldStatement(node, env)
+	case *ast.IgnoreStatement:
+		return NONE
+	case *ast.WithStatement:
+		return evalWithStatement(node, env)
+	case *ast.AssertStatement:
+		return evalAssertStatement(node, env)
+	case *ast.ImportStatement:
+		return evalImportStatement(node, env)
 	case *ast.PrefixExpression:
 		right := Eval(node.Right, env)
 		if isError(right) {
@@ -31,6 +47,9 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		}
 		return evalPrefixExpression(node.Operator, right)
 	case *ast.InfixExpression:
+		if node.Operator == "and" || node.Operator == "or" {
+			return evalLogicalExpression(node, env)
+		}
 		right := Eval(node.Right, env)
 		if isError(right) {
 			// fmt.Printf("Error in right operand: %v\n", right)
@@ -44,6 +63,21 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		result := evalInfixExpression(node.Operator, left, right)
 		// fmt.Printf("InfixExpression result: %v\n", result)
 		return result
+	case *ast.ChainedComparison:
+		return evalChainedComparison(node, env)
+	case *ast.ConditionalExpression:
+		condition := Eval(node.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+		truthy, err := isTruthy(condition)
+		if err != nil {
+			return err
+		}
+		if truthy {
+			return Eval(node.Consequence, env)
+		}
+		return Eval(node.Alternative, env)
 	case *ast.PostfixExpression:
 		left := Eval(node.Left, env)
 		if isError(left) {
@@ -61,6 +95,8 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 			return val
 		}
 		return &object.ReturnValue{Value: val}
+	case *ast.NoneLiteral:
+		return NONE
 	case *ast.Boolean:
 		return nativeBoolToBooleanObject(node.Value)
 	case *ast.AssignStatement:
@@ -68,7 +104,24 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if isError(val) {
 			return val
 		}
-		env.Set(node.Name.Value, val)
+		// x op= y stores x op y
+		if node.Operator != "" && node.Operator != "=" {
+			current, ok := env.Get(node.Name.Value)
+			if !ok {
+				return newError("identifier not found: " + node.Name.Value)
+			}
+			val = evalInfixExpression(strings.TrimSuffix(node.Operator, "="), current, val)
+			if isError(val) {
+				return val
+			}
+		}
+		if err := assign(env, node.Name.Value, val); err != nil {
+			return err
+		}
+	case *ast.VarStatement:
+		return evalVarStatement(node, env)
+	case *ast.ScopeStatement:
+		return evalScopeStatement(node, env)
 	case *ast.Identifier:
 		return evalIdentifier(node, env)
 	case *ast.ArrayLiteral:
@@ -84,14 +137,27 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return evalTupleLiteral(node, env)
 	case *ast.HashLiteral:
 		return evalHashLiteral(node, env)
+	case *ast.SetLiteral:
+		elements := evalExpressions(node.Elements, env)
+		if len(elements) == 1 && isError(elements[0]) {
+			return elements[0]
+		}
+		return newSet(elements, false)
 	case *ast.FunctionDefinition:
 		fnObj := &object.Function{
-			Parameters: node.Parameters,
-			Body:       node.Body,
-			Env:        env,
+			Parameters:  node.Parameters,
+			Body:        node.Body,
+			Env:         env,
+			IsGenerator: containsYield(node.Body),
+		}
+		decorated := applyDecorators(node.Decorators, fnObj, env)
+		if isError(decorated) {
+			return decorated
 		}
-		env.Set(node.Name.Value, fnObj)
-		return fnObj
+		if err := assign(env, node.Name.Value, decorated); err != nil {
+			return err
+		}
+		return decorated
 
 	case *ast.IndexExpression:
 		left := Eval(node.Left, env)
@@ -104,19 +170,27 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		}
 		return evalIndexExpression(left, index)
 
+	case *ast.SliceExpression:
+		return evalSliceExpression(node, env)
+
+	case *ast.MemberExpression:
+		return evalMemberExpression(node, env)
+
 	case *ast.CallExpression:
 		function := Eval(node.Function, env)
 		if isError(function) {
 			return function
 		}
 
-		args := evalExpressions(node.Arguments, env)
-		if len(args) == 1 && isError(args[0]) {
-			return args[0]
+		args, kwargs, err := evalCallArguments(node.Arguments, env)
+		if err != nil {
+			return err
 		}
 
-		return applyFunction(function, args)
+		return applyFunctionWithKeywords(function, args, kwargs)
 
+	case *ast.PipelineExpression:
+		return evalPipelineExpression(node, env)
 	}
 	return NONE
 }
@@ -125,24 +199,23 @@ func evalHashLiteral(
 	node *ast.HashLiteral,
 	env *object.Environment,
 ) object.Object {
-	pairs := make(map[object.HashKey]object.HashPair)
-	for keyNode, valueNode := range node.Pairs {
+	hash := object.NewHash()
+	for _, keyNode := range node.Keys {
 		key := Eval(keyNode, env)
 		if isError(key) {
 			return key
 		}
-		hashKey, ok := key.(object.Hashable)
+		hashed, ok := object.HashKeyOf(key)
 		if !ok {
 			return newError("unusable as hash key: %s", key.Type())
 		}
-		value := Eval(valueNode, env)
+		value := Eval(node.Pairs[keyNode], env)
 		if isError(value) {
 			return value
 		}
-		hashed := hashKey.HashKey()
-		pairs[hashed] = object.HashPair{Key: key, Value: value}
+		hash.Set(hashed, object.HashPair{Key: key, Value: value})
 	}
-	return &object.Hash{Pairs: pairs}
+	return hash
 }
 
 func evalTupleLiteral(tl *ast.TupleLiteral, env *object.Environment) object.Object {
@@ -155,6 +228,9 @@ func evalTupleLiteral(tl *ast.TupleLiteral, env *object.Environment) object.Obje
 }
 
 func evalIndexExpression(left, index object.Object) object.Object {
+	if result, ok := callHook(left, "__getitem__", index); ok {
+		return result
+	}
 	switch {
 	case left.Type() == object.TUPLE_OBJ:
 		return evalTupleIndexExpression(left, index)
@@ -162,6 +238,8 @@ func evalIndexExpression(left, index object.Object) object.Object {
 		return evalArrayIndexExpression(left, index)
 	case left.Type() == object.HASH_OBJ:
 		return evalHashIndexExpression(left, index)
+	case left.Type() == object.RANGE_OBJ:
+		return evalRangeIndexExpression(left.(*object.Range), index)
 	default:
 		return newError("index operator not supported: %s", left.Type())
 	}
@@ -178,11 +256,11 @@ func evalTupleIndexExpression(tuple, index object.Object) object.Object {
 
 func evalHashIndexExpression(hash, index object.Object) object.Object {
 	hashObject := hash.(*object.Hash)
-	key, ok := index.(object.Hashable)
+	key, ok := object.HashKeyOf(index)
 	if !ok {
 		return newError("unusable as hash key: %s", index.Type())
 	}
-	pair, ok := hashObject.Pairs[key.HashKey()]
+	pair, ok := hashObject.Pairs[key]
 	if !ok {
 		return NONE
 	}
@@ -199,6 +277,47 @@ func evalArrayIndexExpression(array, index object.Object) object.Object {
 	return arrayObject.Elements[idx]
 }
 
+func evalPipelineExpression(node *ast.PipelineExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isError(left) {
+		return left
+	}
+
+	// x |> f(a, b) calls f(x, a, b), or f(a, x, b) for a builtin whose
+	// PipeArg is 1; anything else must evaluate to a spell
+	call, ok := node.Right.(*ast.CallExpression)
+	if !ok {
+		function := Eval(node.Right, env)
+		if isError(function) {
+			return function
+		}
+		return applyFunction(function, []object.Object{left})
+	}
+
+	function := Eval(call.Function, env)
+	if isError(function) {
+		return function
+	}
+	args, kwargs, err := evalCallArguments(call.Arguments, env)
+	if err != nil {
+		return err
+	}
+	return applyFunctionWithKeywords(function, insertPipedArgument(function, left, args), kwargs)
+}
+
+// insertPipedArgument places the left operand of a pipeline among the
+// arguments of the call on its right.
+func insertPipedArgument(function, left object.Object, args []object.Object) []object.Object {
+	pos := 0
+	if builtin, ok := function.(*object.Builtin); ok && builtin.PipeArg <= len(args) {
+		pos = builtin.PipeArg
+	}
+	result := make([]object.Object, 0, len(args)+1)
+	result = append(result, args[:pos]...)
+	result = append(result, left)
+	return append(result, args[pos:]...)
+}
+
 func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
 	var result []object.Object
 
@@ -214,18 +333,64 @@ func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Ob
 }
 
 func applyFunction(fn object.Object, args []object.Object) object.Object {
+	return applyFunctionWithKeywords(fn, args, nil)
+}
+
+func applyFunctionWithKeywords(fn object.Object, args []object.Object, kwargs map[string]object.Object) object.Object {
 	switch fn := fn.(type) {
 	case *object.Function:
+		if len(kwargs) > 0 {
+			var err *object.Error
+			if args, err = bindKeywordArguments(fn, args, kwargs); err != nil {
+				return err
+			}
+		}
+		if len(args) != len(fn.Parameters) {
+			return newError("wrong number of arguments. got=%d, want=%d",
+				len(args), len(fn.Parameters))
+		}
+		if fn.IsGenerator {
+			return newGenerator(fn, args)
+		}
 		extendedEnv := extendFunctionEnv(fn, args)
 		evaluated := Eval(fn.Body, extendedEnv)
 		return unwrapReturnValue(evaluated)
 	case *object.Builtin:
+		if fn.KwFn != nil {
+			return fn.KwFn(kwargs, args...)
+		}
+		if len(kwargs) > 0 {
+			if fn.Name != "" {
+				return newError("%s() does not accept keyword arguments", fn.Name)
+			}
+			return newError("builtin does not accept keyword arguments")
+		}
 		return fn.Fn(args...)
 	default:
 		return newError("not a function: %s", fn.Type())
 	}
 }
 
+// applyDecorators passes a freshly defined object through its decorators,
+// innermost (closest to the definition) first.
+func applyDecorators(
+	decorators []ast.Expression,
+	obj object.Object,
+	env *object.Environment,
+) object.Object {
+	for i := len(decorators) - 1; i >= 0; i-- {
+		decorator := Eval(decorators[i], env)
+		if isError(decorator) {
+			return decorator
+		}
+		obj = applyFunction(decorator, []object.Object{obj})
+		if isError(obj) {
+			return obj
+		}
+	}
+	return obj
+}
+
 func extendFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
 	// Create a new child environment so function variables don’t pollute outer env
 	env := object.NewEnclosedEnvironment(fn.Env)
@@ -252,6 +417,9 @@ func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object
 	if builtin, ok := builtins[node.Value]; ok {
 		return builtin
 	}
+	if value, ok := interpreterOf(env).lookup(node.Value); ok {
+		return value
+	}
 	return newError("identifier not found: " + node.Value)
 }
 
@@ -297,11 +465,19 @@ func nativeBoolToBooleanObject(input bool) *object.Boolean {
 }
 
 func evalPrefixExpression(operator string, right object.Object) object.Object {
+	if result, ok := callHook(right, prefixHooks[operator]); ok {
+		return result
+	}
 	switch operator {
 	case "!":
 		return evalBangOperatorExpression(right)
 	case "-":
 		return evalMinusPrefixOperatorExpression(right)
+	case "~":
+		if right.Type() != object.INTEGER_OBJ {
+			return newError("unknown operator: ~%s", right.Type())
+		}
+		return &object.Integer{Value: ^right.(*object.Integer).Value}
 	default:
 		return newError("unknown operator: %s%s", operator, right.Type())
 	}
@@ -309,6 +485,38 @@ func evalPrefixExpression(operator string, right object.Object) object.Object {
 
 func evalInfixExpression(operator string, left, right object.Object) object.Object {
 	// fmt.Printf("InfixExpression operator: %s, left: %v, right: %v\n", operator, left, right)
+	if result, ok := callBinaryHook(operator, left, right); ok {
+		return result
+	}
+
+	switch operator {
+	case "in", "not in":
+		found := evalMembership(left, right)
+		if isError(found) || operator == "in" {
+			return found
+		}
+		return nativeBoolToBooleanObject(found != TRUE)
+	case "is":
+		return nativeBoolToBooleanObject(isIdentical(left, right))
+	case "is not":
+		return nativeBoolToBooleanObject(!isIdentical(left, right))
+	}
+
+	if leftSet, ok := left.(*object.Set); ok {
+		if rightSet, ok := right.(*object.Set); ok {
+			return evalSetInfixExpression(operator, leftSet, rightSet)
+		}
+	}
+
+	switch operator {
+	case "==":
+		return nativeBoolToBooleanObject(valuesEqual(left, right))
+	case "!=":
+		return nativeBoolToBooleanObject(!valuesEqual(left, right))
+	case "<", ">", "<=", ">=":
+		return evalOrderingExpression(operator, left, right)
+	}
+
 	switch {
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
 		return evalIntegerInfixExpression(operator, left, right)
@@ -316,10 +524,8 @@ func evalInfixExpression(operator string, left, right object.Object) object.Obje
 		return evalBooleanInfixExpression(operator, left, right)
 	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
 		return evalStringInfixExpression(operator, left, right)
-
-	case left.Type() != right.Type():
-		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
-	case left.Type() == object.FLOAT_OBJ || right.Type() == object.FLOAT_OBJ:
+	case isNumeric(left) && isNumeric(right):
+		// One side is a float; the integer side is promoted
 		leftVal := toFloat(left)
 		rightVal := toFloat(right)
 		switch operator {
@@ -331,15 +537,28 @@ func evalInfixExpression(operator string, left, right object.Object) object.Obje
 			return &object.Float{Value: leftVal * rightVal}
 		case "/":
 			return &object.Float{Value: leftVal / rightVal}
+		case "**":
+			return &object.Float{Value: math.Pow(leftVal, rightVal)}
+		case "//":
+			if rightVal == 0 {
+				return newError("division by zero")
+			}
+			return &object.Float{Value: math.Floor(leftVal / rightVal)}
 		default:
 			return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
 		}
+	case left.Type() != right.Type():
+		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
 	default:
 		// fmt.Printf("Error: type mismatch or unknown operator\n")
 		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
 	}
 }
 
+func isNumeric(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.FLOAT_OBJ
+}
+
 func toFloat(obj object.Object) float64 {
 	switch obj := obj.(type) {
 	case *object.Integer:
@@ -388,25 +607,71 @@ func evalPosfixExpression(operator string, left object.Object) object.Object {
 	}
 }
 
+// evalAssertStatement fails with the condition as it was parsed, fully
+// parenthesized, followed by the message when one is given. The message is
+// only evaluated on failure.
+func evalAssertStatement(node *ast.AssertStatement, env *object.Environment) object.Object {
+	if !interpreterOf(env).AssertionsEnabled {
+		return NONE
+	}
+	condition := Eval(node.Condition, env)
+	if isError(condition) {
+		return condition
+	}
+	truthy, err := isTruthy(condition)
+	if err != nil {
+		return err
+	}
+	if truthy {
+		return NONE
+	}
+	if node.Message == nil {
+		return newError("assertion failed: %s", node.Condition.String())
+	}
+	message := Eval(node.Message, env)
+	if isError(message) {
+		return message
+	}
+	if str, ok := message.(*object.String); ok {
+		return newError("assertion failed: %s: %s", node.Condition.String(), str.Value)
+	}
+	return newError("assertion failed: %s: %s", node.Condition.String(), message.Inspect())
+}
+
+// evalLogicalExpression short-circuits `and`/`or`, returning whichever
+// operand decided the result as Python does.
+func evalLogicalExpression(node *ast.InfixExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isError(left) {
+		return left
+	}
+	truthy, err := isTruthy(left)
+	if err != nil {
+		return err
+	}
+	if truthy == (node.Operator == "or") {
+		return left
+	}
+	return Eval(node.Right, env)
+}
+
 func evalBangOperatorExpression(right object.Object) object.Object {
-	switch right {
-	case TRUE:
-		return FALSE
-	case FALSE:
-		return TRUE
-	case NONE:
-		return TRUE
-	default:
-		return FALSE
+	truthy, err := isTruthy(right)
+	if err != nil {
+		return err
 	}
+	return nativeBoolToBooleanObject(!truthy)
 }
 
 func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
-	if right.Type() != object.INTEGER_OBJ {
+	switch right := right.(type) {
+	case *object.Integer:
+		return &object.Integer{Value: -right.Value}
+	case *object.Float:
+		return &object.Float{Value: -right.Value}
+	default:
 		return newError("unknown operator: -%s", right.Type())
 	}
-	value := right.(*object.Integer).Value
-	return &object.Integer{Value: -value}
 }
 
 func evalIncrementOperatorExpression(side object.Object) object.Object {
@@ -438,10 +703,37 @@ func evalIntegerInfixExpression(
 		return &object.Integer{Value: leftVal - rightVal}
 	case "*":
 		return &object.Integer{Value: leftVal * rightVal}
-	case "/":
-		return &object.Integer{Value: leftVal / rightVal}
-	case "%":
-		return &object.Integer{Value: leftVal % rightVal}
+	case "/", "//", "%":
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
+		switch operator {
+		case "/":
+			return &object.Integer{Value: leftVal / rightVal}
+		case "//":
+			return &object.Integer{Value: floorDiv(leftVal, rightVal)}
+		default:
+			return &object.Integer{Value: floorMod(leftVal, rightVal)}
+		}
+	case "**":
+		if rightVal < 0 {
+			return &object.Float{Value: math.Pow(float64(leftVal), float64(rightVal))}
+		}
+		return &object.Integer{Value: intPow(leftVal, rightVal)}
+	case "&":
+		return &object.Integer{Value: leftVal & rightVal}
+	case "|":
+		return &object.Integer{Value: leftVal | rightVal}
+	case "^":
+		return &object.Integer{Value: leftVal ^ rightVal}
+	case "<<", ">>":
+		if rightVal < 0 {
+			return newError("negative shift count: %d", rightVal)
+		}
+		if operator == "<<" {
+			return &object.Integer{Value: leftVal << rightVal}
+		}
+		return &object.Integer{Value: leftVal >> rightVal}
 	case "<":
 		return nativeBoolToBooleanObject(leftVal < rightVal)
 	case ">":
@@ -467,9 +759,48 @@ func evalIntegerInfixExpression(
 	}
 }
 
+// floorDiv rounds the quotient towards negative infinity like Python's //.
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+// floorMod is the remainder that goes with floorDiv, taking the sign of the
+// divisor like Python's %, so that a == floorDiv(a, b)*b + floorMod(a, b).
+func floorMod(a, b int64) int64 {
+	m := a % b
+	if m != 0 && (m < 0) != (b < 0) {
+		m += b
+	}
+	return m
+}
+
+// intPow raises base to a non-negative exponent by repeated squaring.
+func intPow(base, exp int64) int64 {
+	result := int64(1)
+	for exp > 0 {
+		if exp&1 == 1 {
+			result *= base
+		}
+		base *= base
+		exp >>= 1
+	}
+	return result
+}
+
 func evalIfExpression(ie *ast.IfStatement, env *object.Environment) object.Object {
 	condition := Eval(ie.Condition, env)
-	if isTruthy(condition) {
+	if isError(condition) {
+		return condition
+	}
+	truthy, err := isTruthy(condition)
+	if err != nil {
+		return err
+	}
+	if truthy {
 		return Eval(ie.Consequence, env)
 	}
 
@@ -478,7 +809,11 @@ func evalIfExpression(ie *ast.IfStatement, env *object.Environment) object.Objec
 		if isError(condition) {
 			return condition
 		}
-		if isTruthy(condition) {
+		truthy, err := isTruthy(condition)
+		if err != nil {
+			return err
+		}
+		if truthy {
 			return Eval(branch.Consequence, env)
 		}
 	}
@@ -490,16 +825,85 @@ func evalIfExpression(ie *ast.IfStatement, env *object.Environment) object.Objec
 	return NONE
 }
 
-func isTruthy(obj object.Object) bool {
-	switch obj {
-	case NONE:
-		return false
-	case TRUE:
-		return true
-	case FALSE:
-		return false
+func evalForStatement(fs *ast.ForStatement, env *object.Environment) object.Object {
+	iterable := Eval(fs.Iterable, env)
+	if isError(iterable) {
+		return iterable
+	}
+	iter, err := iterate(iterable)
+	if err != nil {
+		return err
+	}
+
+	for {
+		item, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if isError(item) {
+			return item
+		}
+		if err := assign(env, fs.Variable.Value, item); err != nil {
+			if gen, ok := iter.(*object.Generator); ok {
+				gen.Stop()
+			}
+			return err
+		}
+
+		result := Eval(fs.Body, env)
+		if result != nil {
+			rt := result.Type()
+			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ {
+				if gen, ok := iter.(*object.Generator); ok {
+					gen.Stop()
+				}
+				return result
+			}
+		}
+	}
+
+	if fs.Alternative != nil {
+		return Eval(fs.Alternative, env)
+	}
+	return NONE
+}
+
+// isTruthy follows Python: none, False, zero numbers and empty strings and
+// collections are false, everything else is true. An instance decides
+// through __bool__, falling back to __len__; an error from either hook is
+// returned instead of a result.
+func isTruthy(obj object.Object) (bool, *object.Error) {
+	for _, hook := range []string{"__bool__", "__len__"} {
+		if result, ok := callHook(obj, hook); ok {
+			if err, ok := result.(*object.Error); ok {
+				return false, err
+			}
+			return isTruthy(result)
+		}
+	}
+	switch obj := obj.(type) {
+	case *object.None:
+		return false, nil
+	case *object.Boolean:
+		return obj.Value, nil
+	case *object.Integer:
+		return obj.Value != 0, nil
+	case *object.Float:
+		return obj.Value != 0, nil
+	case *object.String:
+		return obj.Value != "", nil
+	case *object.Array:
+		return len(obj.Elements) > 0, nil
+	case *object.Tuple:
+		return len(obj.Elements) > 0, nil
+	case *object.Hash:
+		return len(obj.Pairs) > 0, nil
+	case *object.Set:
+		return len(obj.Keys) > 0, nil
+	case *object.Range:
+		return obj.Len() > 0, nil
 	default:
-		return true
+		return true, nil
 	}
 }
 
@@ -1,6 +1,11 @@
 package evaluator
 
 import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"thecarrionlanguage/lexer"
@@ -116,6 +121,15 @@ func TestBangOperator(t *testing.T) {
 		{"!!True", true},
 		{"!!False", false},
 		{"!!5", true},
+		{"!0", true},
+		{"!0.0", true},
+		{`!""`, true},
+		{`!"a"`, false},
+		{"![]", true},
+		{"![0]", false},
+		{"!{}", true},
+		{"!none", true},
+		{"!set()", true},
 	}
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
@@ -123,6 +137,32 @@ func TestBangOperator(t *testing.T) {
 	}
 }
 
+func TestTruthiness(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"if 0: 1\nelse: 2", 2},
+		{"if 3: 1\nelse: 2", 1},
+		{`if "": 1
+else: 2`, 2},
+		{"if []: 1\nelse: 2", 2},
+		{"t = (1, 2)\nif t: 1\nelse: 2", 1},
+		{"if {}: 1\nelse: 2", 2},
+		{"1 if 0.0 else 2", 2},
+		{"0 and missing", 0},
+		{"3 and 4", 4},
+		{"0 or 5", 5},
+		{"6 or missing", 6},
+		{"[] or [1] and 7", 7},
+		{"True and 0 or 8", 8},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testIntegerObject(t, evaluated, int64(tt.expected))
+	}
+}
+
 func TestIfElseExpression(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -292,8 +332,14 @@ func TestBuiltinFunctions(t *testing.T) {
 		{`len("")`, 0},
 		{`len("four")`, 4},
 		{`len("hello world")`, 11},
+		{`len("hé")`, 2},
+		{`len("日本語")`, 3},
 		{`len(1)`, "argument to `len` not supported, got INTEGER"},
 		{`len("one", "two")`, "wrong number of arguments. got=2, want=1"},
+		{`len([1, 2, 3])`, 3},
+		{`len((1, 2))`, 2},
+		{`len({"a": 1})`, 1},
+		{`len({1, 2, 2})`, 2},
 	}
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
@@ -464,3 +510,1692 @@ func TestHashIndexExpressions(t *testing.T) {
 		}
 	}
 }
+
+func TestMatchStatement(t *testing.T) {
+	describe := `
+spell describe(v):
+    match v:
+        case 0:
+            return "zero"
+        case -1: return "minus one"
+        case int(n) if n > 100:
+            big = "big "
+            return big + string(n)
+        case int(n):
+            return "int"
+        case (x, y):
+            return "pair " + string(x + y)
+        case [first, *rest]:
+            return string(first) + " then " + string(rest)
+        case {"name": name}:
+            return name
+        case _:
+            return "other"
+`
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"describe(0)", "zero"},
+		{"describe(-1)", "minus one"},
+		{"describe(500)", "big 500"},
+		{"describe(7)", "int"},
+		{"describe((3, 4))", "pair 7"},
+		{"describe([1, 2, 3])", "1 then [2, 3]"},
+		{"describe([1])", "1 then []"},
+		{`describe({"name": "odin", "age": 1})`, "odin"},
+		{`describe({"age": 1})`, "other"},
+		{`describe("s")`, "other"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(describe + tt.input)
+		str, ok := evaluated.(*object.String)
+		if !ok {
+			t.Errorf("%s: object is not String. got=%T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if str.Value != tt.expected {
+			t.Errorf("%s: wrong value. expected=%q, got=%q", tt.input, tt.expected, str.Value)
+		}
+	}
+}
+
+func TestMatchStatementBindsCaptures(t *testing.T) {
+	input := `
+match (1, (2, 3)):
+    case (a, (b, c)):
+        total = a + b + c
+total
+`
+	testIntegerObject(t, testEval(input), 6)
+}
+
+func TestMatchStatementNoMatch(t *testing.T) {
+	input := `
+match 5:
+    case 1: 10
+    case x if x > 10: 20
+`
+	evaluated := testEval(input)
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+	if errObj.Message != "no case matched value: 5" {
+		t.Errorf("wrong error message. got=%q", errObj.Message)
+	}
+}
+
+func TestForStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{`
+total = 0
+for x in [1, 2, 3]:
+    total = total + x
+total`, 6},
+		{`
+total = 0
+for x in (1, 2, 3, 4):
+    total = total + x
+total`, 10},
+		{`
+count = 0
+for ch in "héllo":
+    count = count + 1
+count`, 5},
+		{`
+spell find(items, wanted):
+    for item in items:
+        if item == wanted:
+            return item
+    return -1
+find([4, 5, 6], 5)`, 5},
+		{`
+for x in []:
+    x
+else:
+    7`, 7},
+	}
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestGenerators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`
+spell evens(limit):
+    for x in [0, 1, 2, 3, 4, 5, 6]:
+        if x > limit:
+            return 0
+        if x % 2 == 0:
+            yield x
+string(list(evens(4)))`, "[0, 2, 4]"},
+		{`
+spell letters():
+    yield "a"
+    yield "b"
+out = ""
+for l in letters():
+    out = out + l
+out`, "ab"},
+		{`
+spell gen():
+    yield 1
+    yield 2
+type(gen())`, "GENERATOR"},
+		{`
+spell gen():
+    yield
+string(list(gen()))`, "[None]"},
+		{`
+spell naturals():
+    for x in [1, 2, 3, 4, 5, 6, 7, 8, 9]:
+        yield x
+spell first(items):
+    for item in items:
+        return item
+string(first(naturals()))`, "1"},
+		{`string(tuple([1, 2]))`, "(1, 2)"},
+		{`string(list("ab"))`, "[a, b]"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		str, ok := evaluated.(*object.String)
+		if !ok {
+			t.Errorf("object is not String. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+		if str.Value != tt.expected {
+			t.Errorf("wrong value. expected=%q, got=%q", tt.expected, str.Value)
+		}
+	}
+}
+
+func TestGeneratorErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{`
+spell bad():
+    yield 1
+    yield 1 + True
+list(bad())`, "type mismatch: INTEGER + BOOLEAN"},
+		{"yield 1", "yield outside of a spell"},
+		{"for x in 5:\n    x", "INTEGER is not iterable"},
+		{`
+spell gen():
+    yield 1
+    for x in g:
+        yield x
+g = gen()
+list(g)`, "generator already executing"},
+		{`
+spell gen():
+    yield next_of(g)
+spell next_of(it):
+    for x in it:
+        return x
+g = gen()
+list(g)`, "generator already executing"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q",
+				tt.expectedMessage, errObj.Message)
+		}
+	}
+}
+
+func TestDecorators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{`
+spell plus_one(f):
+    spell wrapper(x):
+        return f(x) + 1
+    return wrapper
+
+@plus_one
+spell double(x):
+    return x * 2
+double(5)`, 11},
+		{`
+spell times(n):
+    spell decorate(f):
+        spell wrapper(x):
+            return f(x) * n
+        return wrapper
+    return decorate
+
+spell plus_one(f):
+    spell wrapper(x):
+        return f(x) + 1
+    return wrapper
+
+@plus_one
+@times(10)
+spell identity(x):
+    return x
+identity(4)`, 41},
+		{`
+spell replace(f):
+    return 99
+
+@replace
+spell ignored():
+    return 1
+ignored`, 99},
+	}
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestDecoratorErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"@missing\nspell f(): return 1", "identifier not found: missing"},
+		{"@5\nspell f(): return 1", "not a function: INTEGER"},
+		{"spell two(a, b): return a\n@two\nspell f(): return 1", "wrong number of arguments. got=1, want=2"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q",
+				tt.expectedMessage, errObj.Message)
+		}
+	}
+}
+
+func TestMembershipAndIdentity(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"2 in [1, 2, 3]", true},
+		{"4 in [1, 2, 3]", false},
+		{"4 not in [1, 2, 3]", true},
+		{"2.0 in (1, 2)", true},
+		{`"ell" in "hello"`, true},
+		{`"x" not in "hello"`, true},
+		{`"a" in {"a": 1}`, true},
+		{`1 in {"a": 1}`, false},
+		{"spell gen():\n    yield 1\n    yield 2\n2 in gen()", true},
+		{"none is none", true},
+		{"1 is 1", true},
+		{"1 is 1.0", false},
+		{"a = [1]\nb = a\nb is a", true},
+		{"[1] is [1]", false},
+		{"[1] is not [1]", true},
+		{"1 in 5", "argument of type INTEGER is not iterable"},
+		{`1 in "abc"`, "'in <string>' requires string as left operand, got INTEGER"},
+		{`[1] in {"a": 1}`, "unusable as hash key: ARRAY"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		}
+	}
+}
+
+func TestStructuralComparison(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"[1, 2] == [1, 2]", true},
+		{"[1, 2] == [1, 2, 3]", false},
+		{"[1, [2, 3]] == [1, [2, 3]]", true},
+		{"[1, 2] != [2, 1]", true},
+		{"(1, 2) == (1, 2)", true},
+		{"(1, 2) == [1, 2]", false},
+		{`{"a": [1], "b": 2} == {"b": 2, "a": [1]}`, true},
+		{`{"a": 1} == {"a": 2}`, false},
+		{"1 == 1.0", true},
+		{`1 == "1"`, false},
+		{"none == none", true},
+		{`"a" < "b"`, true},
+		{`"abc" >= "abd"`, false},
+		{"[1, 2] < [1, 3]", true},
+		{"[1, 2] < [1, 2, 0]", true},
+		{"(2, 1) > (1, 9)", true},
+		{"[1, 2] <= [1, 2]", true},
+		{"1.5 < 2", true},
+		{"[1, 2] < [1, \"a\"]", "type mismatch: INTEGER < STRING"},
+		{"[1] < (1, 2)", "type mismatch: ARRAY < TUPLE"},
+		{`{"a": 1} < {"a": 2}`, "unknown operator: HASH < HASH"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		}
+	}
+}
+
+func TestSelfReferencingComparison(t *testing.T) {
+	one := &object.Integer{Value: 1}
+	a := &object.Array{Elements: []object.Object{one, nil}}
+	a.Elements[1] = a
+	b := &object.Array{Elements: []object.Object{one, nil}}
+	b.Elements[1] = b
+
+	testBooleanObject(t, evalInfixExpression("==", a, b), true)
+	testBooleanObject(t, evalInfixExpression("<=", a, b), true)
+
+	c := &object.Array{Elements: []object.Object{&object.Integer{Value: 2}, a}}
+	testBooleanObject(t, evalInfixExpression("==", a, c), false)
+	testBooleanObject(t, evalInfixExpression("<", a, c), true)
+}
+
+func TestTupleAndFloatHashKeys(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`{(1, 2): 5}[(1, 2)]`, 5},
+		{`grid = {(0, 0): 1, (0, 1): 2}
+grid[(0, 1)]`, 2},
+		{`{((1, 2), "a"): 7}[((1, 2), "a")]`, 7},
+		{`{1.5: 3}[1.5]`, 3},
+		{`{1: 4}[1.0]`, 4},
+		{`(1, 2) in {(1, 2): 0}`, true},
+		{`{(1, [2]): 0}`, "unusable as hash key: TUPLE"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		}
+	}
+}
+
+func TestHashInsertionOrder(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`string({"b": 1, "a": 2, "c": 3, 10: 4, 2: 5})`, "{b: 1, a: 2, c: 3, 10: 4, 2: 5}"},
+		{`string({"b": 1, "a": 2, "b": 3})`, "{b: 3, a: 2}"},
+		{`string({1.0: "a", 1: "b"})`, "{1.000000: b}"},
+		{`string({1: "a", 1.0: "b"})`, "{1: b}"},
+		{`string(keys({"z": 1, "y": 2, "x": 3}))`, "[z, y, x]"},
+		{`string(values({"z": 1, "y": 2, "x": 3}))`, "[1, 2, 3]"},
+		{`string(list({3: 0, 1: 0, 2: 0}))`, "[3, 1, 2]"},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 5; i++ {
+			evaluated := testEval(tt.input)
+			str, ok := evaluated.(*object.String)
+			if !ok {
+				t.Fatalf("object is not String. got=%T (%+v)", evaluated, evaluated)
+			}
+			if str.Value != tt.expected {
+				t.Errorf("wrong order. expected=%q, got=%q", tt.expected, str.Value)
+				break
+			}
+		}
+	}
+}
+
+func TestSets(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"string({1, 2, 2, 3, 1})", "{1, 2, 3}"},
+		{"string(set())", "set()"},
+		{`string(set("abca"))`, "{a, b, c}"},
+		{"string({1, 2} | {2, 3})", "{1, 2, 3}"},
+		{"string({1, 2, 3} & {2, 3, 4})", "{2, 3}"},
+		{"string({1, 2, 3} - {2})", "{1, 3}"},
+		{"string({1, 2, 3} ^ {3, 4})", "{1, 2, 4}"},
+		{"string(frozenset([1, 2]) | {3})", "frozenset({1, 2, 3})"},
+		{"string(list({3, 1, 2}))", "[3, 1, 2]"},
+		{"2 in {1, 2}", true},
+		{"5 not in {1, 2}", true},
+		{"1.0 in {1, 2}", true},
+		{"{1, 2} <= {1, 2}", true},
+		{"{1, 2} < {1, 2}", false},
+		{"{1} < {1, 2}", true},
+		{"{1, 2, 3} > {3}", true},
+		{"{1, 2} == {2, 1}", true},
+		{"{1, 2} == frozenset([1, 2])", true},
+		{"{1, 2} != {1}", true},
+		{"s = {1}\ns |= {2}\nstring(s)", "{1, 2}"},
+		{`{frozenset([1, 2]): "a"}[frozenset([2, 1])]`, "a"},
+		{"string({frozenset([1]), frozenset([1])})", "{frozenset({1})}"},
+		{"{[1], 2}", errorMessage("unusable as set element: ARRAY")},
+		{"{{1}: 2}", errorMessage("unusable as hash key: SET")},
+		{"[1] in {1}", errorMessage("unusable as set element: ARRAY")},
+		{"{1} + {2}", errorMessage("unknown operator: SET + SET")},
+		{"set(1)", errorMessage("cannot convert INTEGER to set")},
+		{"s = set()\ns.add(1)\ns.add(2)\ns.add(1)\nstring(s)", "{1, 2}"},
+		{"s = {1, 2}\ns.remove(1)\nstring(s)", "{2}"},
+		{"s = {1}\ns.discard(5)\ns.discard(1)\nstring(s)", "set()"},
+		{"{1}.remove(5)", errorMessage("5 is not in set")},
+		{"set().add([1])", errorMessage("unusable as set element: ARRAY")},
+		{"frozenset([1]).add(2)", errorMessage("FROZENSET has no attribute add")},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			str, ok := evaluated.(*object.String)
+			if !ok {
+				t.Errorf("object is not String. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if str.Value != expected {
+				t.Errorf("wrong value. expected=%q, got=%q", expected, str.Value)
+			}
+		case errorMessage:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != string(expected) {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		}
+	}
+}
+
+// errorMessage marks an expected error in tables whose plain strings are
+// expected string values.
+type errorMessage string
+
+func TestConditionalExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"1 if True else 2", 1},
+		{"1 if False else 2", 2},
+		{"x = 5\n\"big\" if x > 3 else \"small\"", "big"},
+		{"0 if 1 > 2 else 1 if 2 > 3 else 2", 2},
+		{"1 if True else missing", 1},
+		{"missing if False else 2", 2},
+		{"spell sign(n): return -1 if n < 0 else 1\nsign(-5) + sign(3)", 0},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			str, ok := evaluated.(*object.String)
+			if !ok {
+				t.Errorf("object is not String. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if str.Value != expected {
+				t.Errorf("wrong value. expected=%q, got=%q", expected, str.Value)
+			}
+		}
+	}
+}
+
+func TestChainedComparison(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"i = 3\n0 <= i < 5", true},
+		{"i = 5\n0 <= i < 5", false},
+		{"1 < 2 < 3 < 4", true},
+		{"1 < 2 == 2", true},
+		{"3 > 2 != 2", false},
+		{"1 == 1 in [1]", true},
+		{"(1 < 2) == 2", false},
+		{"1 < 3 < 2", false},
+		{"1 == 1 == 1", true},
+		{"1 == 1 != 2", true},
+		{"1 < 2 in [2, 3]", true},
+		{"2 > 3 < missing", false},
+		{`1 < 2 < "a"`, "type mismatch: INTEGER < STRING"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		}
+	}
+}
+
+func TestIgnoreAndAssertStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"spell f(): ignore\nf()", nil},
+		{"spell f(x):\n    if x > 0:\n        ignore\n    else:\n        return -1\n    return x\nf(3) + f(-1)", 2},
+		{"assert 1 < 2\n5", 5},
+		{"assert [1], \"never shown\"\n5", 5},
+		{"x = 0\nassert x > 0", "assertion failed: (x > 0)"},
+		{"x = 0\nassert x > 0, \"x must be positive\"", "assertion failed: (x > 0): x must be positive"},
+		{"assert [], len(\"abc\")", "assertion failed: []: 3"},
+		{"assert missing", "identifier not found: missing"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		default:
+			testNoneObject(t, evaluated)
+		}
+	}
+}
+
+func TestDisabledAssertions(t *testing.T) {
+	interp := NewInterpreter(strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{})
+	interp.AssertionsEnabled = false
+
+	testIntegerObject(t, testEvalIn(interp, "assert 1 > 2, missing\n7"), 7)
+	if _, ok := testEval("assert 1 > 2\n7").(*object.Error); !ok {
+		t.Errorf("assertions were disabled in another interpreter")
+	}
+}
+
+func TestDeclarationsAndScopes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"var x = 5\nx", 5},
+		{"var x\nx", nil},
+		{"const LIMIT = 10\nLIMIT * 2", 20},
+		{"const LIMIT = 10\nLIMIT = 11", "cannot assign to constant LIMIT"},
+		{"const LIMIT = 10\nLIMIT += 1", "cannot assign to constant LIMIT"},
+		{"const LIMIT = 10\nconst LIMIT = 11", "cannot assign to constant LIMIT"},
+		{"const LIMIT = 10\nspell f():\n    LIMIT = 1\n    return LIMIT\nf() + LIMIT", 11},
+		{"const LIMIT = 10\nspell f():\n    global LIMIT\n    LIMIT = 1\nf()", "cannot assign to constant LIMIT"},
+		{"spell f():\n    const N = 1\n    spell g():\n        nonlocal N\n        N = 2\n    g()\nf()", "cannot assign to constant N"},
+		{"const LIMIT = 10\nspell f():\n    var LIMIT = 1\n    return LIMIT\nf() + LIMIT", 11},
+		{"const i = 0\nfor i in [1]:\n    ignore", "cannot assign to constant i"},
+		{"const x = 1\nmatch 5:\n    case x:\n        ignore\nx", "cannot assign to constant x"},
+		{"const x = 1\nmatch [5]:\n    case [x]:\n        ignore", "cannot assign to constant x"},
+		{"count = 0\nspell bump():\n    count = 5\nbump()\ncount", 0},
+		{"count = 0\nspell bump():\n    global count\n    count += 1\nbump()\nbump()\ncount", 2},
+		{"spell bump():\n    global fresh\n    fresh = 3\nbump()\nfresh", 3},
+		{`spell counter():
+    n = 0
+    spell inc():
+        nonlocal n
+        n += 1
+        return n
+    inc()
+    inc()
+    return n
+counter()`, 2},
+		{"spell f():\n    nonlocal n\nf()", "no binding for nonlocal n found"},
+		{"n = 1\nspell f():\n    nonlocal n\nf()", "no binding for nonlocal n found"},
+		{"nonlocal n", "nonlocal declaration not allowed at top level"},
+		{"spell f():\n    n = 1\n    global n\nf()", "n is assigned before global declaration"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		default:
+			testNoneObject(t, evaluated)
+		}
+	}
+}
+
+func TestSequenceBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"a = [1]\nappend(a, 2)\npush(a, 3)\nstring(a)", "[1, 2, 3]"},
+		{"a = [1, 2, 3]\nx = pop(a)\nstring((x, a))", "(3, [1, 2])"},
+		{"a = [1, 2, 3]\nx = pop(a, 0)\nstring((x, a))", "(1, [2, 3])"},
+		{"a = [1, 2, 3]\nx = pop(a, -2)\nstring((x, a))", "(2, [1, 3])"},
+		{"a = [1, 3]\ninsert(a, 1, 2)\ninsert(a, 99, 4)\ninsert(a, -99, 0)\nstring(a)", "[0, 1, 2, 3, 4]"},
+		{"a = [1, 2, 1]\nremove(a, 1)\nstring(a)", "[2, 1]"},
+		{"a = [1, 2, 3]\nreverse(a)\nstring(a)", "[3, 2, 1]"},
+		{"index([5, 6, 7], 7)", 2},
+		{"index((5, 6), 5)", 0},
+		{`index("hello", "ll")`, 2},
+		{"count([1, 2, 1, 1], 1)", 3},
+		{`count("banana", "an")`, 2},
+		{"string(sorted([3, 1, 2]))", "[1, 2, 3]"},
+		{"string(sorted([3, 1, 2], none, True))", "[3, 2, 1]"},
+		{`string(sorted(["bb", "a", "ccc"], len))`, "[a, bb, ccc]"},
+		{"spell neg(x): return -x\nstring(sorted([1, 3, 2], neg))", "[3, 2, 1]"},
+		{"string(sorted([(1, 2), (0, 5), (1, 1)]))", "[(0, 5), (1, 1), (1, 2)]"},
+		{`string(sorted("cab"))`, "[a, b, c]"},
+		{"min([4, 2, 8])", 2},
+		{"max(4, 2, 8)", 8},
+		{"max([1, 2.5])", 2.5},
+		{"sum([1, 2, 3])", 6},
+		{"sum([1, 2], 10)", 13},
+		{"any([0, 0, 3])", true},
+		{"any([])", false},
+		{"all([1, 2, 0])", false},
+		{"all([])", true},
+		{`string(list(enumerate(["a", "b"])))`, "[(0, a), (1, b)]"},
+		{`string(list(enumerate("ab", 1)))`, "[(1, a), (2, b)]"},
+		{`string(list(zip([1, 2, 3], "ab")))`, "[(1, a), (2, b)]"},
+		{"string(list(zip()))", "[]"},
+		{"spell nums():\n    yield 1\n    yield 2\nany(nums())", true},
+		{"pop([])", errorMessage("pop from empty list")},
+		{"pop([1], 3)", errorMessage("pop index out of range")},
+		{"remove([1], 2)", errorMessage("2 is not in list")},
+		{"index([1], 2)", errorMessage("2 is not in ARRAY")},
+		{`index("abc", "z")`, errorMessage(`substring "z" not found`)},
+		{"append((1,2), 3)", errorMessage("argument to `append` must be ARRAY, got TUPLE")},
+		{`sorted([1, "a"])`, errorMessage("type mismatch: STRING < INTEGER")},
+		{"min([])", errorMessage("min() arg is an empty sequence")},
+		{"sum(5)", errorMessage("argument to `sum` must be iterable, got INTEGER")},
+		{"zip([1], 2)", errorMessage("argument 2 to `zip` must be iterable, got INTEGER")},
+		{`sum([1, "a"])`, errorMessage("type mismatch: INTEGER + STRING")},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			str, ok := evaluated.(*object.String)
+			if !ok {
+				t.Errorf("object is not String for %q. got=%T (%+v)", tt.input, evaluated, evaluated)
+				continue
+			}
+			if str.Value != expected {
+				t.Errorf("wrong value for %q. expected=%q, got=%q", tt.input, expected, str.Value)
+			}
+		case errorMessage:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error for %q. got=%T (%+v)", tt.input, evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != string(expected) {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		}
+	}
+}
+
+func TestPrint(t *testing.T) {
+	tests := []struct {
+		input          string
+		stdout, stderr string
+	}{
+		{`print("a", 1, True)`, "a 1 true\n", ""},
+		{`print()`, "\n", ""},
+		{`print("a", "b", sep=", ")`, "a, b\n", ""},
+		{`print("a", end="")` + "\n" + `print("b", end="!")`, "ab!", ""},
+		{`print("a", "b", sep=none, end=none)`, "a b\n", ""},
+		{`print("oops", file=stderr)`, "", "oops\n"},
+		{`print("x", file=stdout)`, "x\n", ""},
+		{`out = stderr` + "\n" + `[1, 2] |> print(end=".", file=out)`, "", "[1, 2]."},
+	}
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		interp := NewInterpreter(strings.NewReader(""), &stdout, &stderr)
+		evaluated := testEvalIn(interp, tt.input)
+		if isError(evaluated) {
+			t.Errorf("%q failed: %s", tt.input, evaluated.Inspect())
+			continue
+		}
+		testNoneObject(t, evaluated)
+		if stdout.String() != tt.stdout || stderr.String() != tt.stderr {
+			t.Errorf("wrong output for %q. expected=(%q, %q), got=(%q, %q)",
+				tt.input, tt.stdout, tt.stderr, stdout.String(), stderr.String())
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{`print("a", sep=1)`, "sep must be STRING or none, got INTEGER"},
+		{`print("a", file=5)`, "file must be a writable stream, got INTEGER"},
+		{`print("a", flush=True)`, "print() got an unexpected keyword argument flush"},
+	}
+	for _, tt := range errorTests {
+		checkEvalResult(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestImports(t *testing.T) {
+	RegisterNativeModule("testnative", func() map[string]object.Object {
+		return map[string]object.Object{
+			"answer": &object.Integer{Value: 42},
+			"double": &object.Builtin{Fn: func(args ...object.Object) object.Object {
+				return &object.Integer{Value: args[0].(*object.Integer).Value * 2}
+			}},
+		}
+	})
+	t.Cleanup(func() { delete(nativeModules, "testnative") })
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"import testnative\ntestnative.answer", 42},
+		{"import testnative as n\nn.double(n.answer)", 84},
+		{"import text\ntext.title(\"hello big world\")", "Hello Big World"},
+		{"import text\ntext.pad_left(\"7\", 3, \"0\")", "007"},
+		{"import itertools as it\nstring(list(it.take(range(1000000), 3)))", "[0, 1, 2]"},
+		{"import itertools\nstring(list(itertools.unique([1, 2, 1, 3])))", "[1, 2, 3]"},
+		{"import itertools\nstring(itertools.chunk(range(5), 2))", "[[0, 1], [2, 3], [4]]"},
+		{"import itertools\nstring(itertools.chunk([], 2))", "[]"},
+		{"import itertools\nitertools.chunk([1, 2], 0)", "chunk size must be positive, got 0"},
+		{"import itertools\nitertools.chunk([1, 2], -1)", "chunk size must be positive, got -1"},
+		{"import itertools\nstring(list(itertools.unique([(1, 2), (1, 2), 1])))", "[(1, 2), 1]"},
+		{"import itertools\nstring(list(itertools.flatten([[1], (2, 3)])))", "[1, 2, 3]"},
+		{"import text\nstring(dir(text))", "[capitalize, is_blank, lines, pad_left, pad_right, repeat, title]"},
+		{"import sys\nstring(sys.stdout)", "<stdout>"},
+		{"import text\nimport text as again\ntext is again", true},
+		{"import nothing", "no module named nothing"},
+		{"import text\ntext.nothing", "module text has no attribute nothing"},
+		{"const text = 1\nimport text", "cannot assign to constant text"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if expected, ok := tt.expected.(bool); ok {
+			testBooleanObject(t, evaluated, expected)
+			continue
+		}
+		checkEvalResult(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestMathModule(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"math.sqrt(16)", 4.0},
+		{"math.sqrt(2.25)", 1.5},
+		{"math.pow(2, 10)", 1024.0},
+		{"math.pow(-8, 1)", -8.0},
+		{"math.exp(0)", 1.0},
+		{"math.log(1)", 0.0},
+		{"math.log(100, 10)", 2.0},
+		{"math.sin(0)", 0.0},
+		{"math.cos(0)", 1.0},
+		{"math.atan2(0, 1)", 0.0},
+		{"math.tanh(0)", 0.0},
+		{"math.acosh(1)", 0.0},
+		{"math.floor(-2.5)", -3},
+		{"math.floor(7)", 7},
+		{"math.ceil(2.1)", 3},
+		{"math.round(2.5)", 2},
+		{"math.round(3.5)", 4},
+		{"math.round(2.675, 1)", 2.7},
+		{"math.round(1250, -2)", 1200},
+		{"math.round(7, 2)", 7},
+		{"math.abs(-3)", 3},
+		{"math.abs(-2.5)", 2.5},
+		{"math.gcd(12, 18, 27)", 3},
+		{"math.gcd(-4, 6)", 2},
+		{"math.gcd()", 0},
+		{"math.lcm(4, 6)", 12},
+		{"math.lcm(3, 0)", 0},
+		{"math.lcm(-4, 6)", 12},
+		{"math.lcm(4611686018427387904, 2)", 4611686018427387904},
+		{"math.gcd(-9223372036854775807 - 1, 6)", 2},
+		{"math.lcm()", 1},
+		{"math.factorial(0)", 1},
+		{"math.factorial(10)", 3628800},
+		{"math.isqrt(99)", 9},
+		{"math.isqrt(100)", 10},
+		{"math.isclose(0.1 + 0.2, 0.3)", true},
+		{"math.isclose(1, 1.1)", false},
+		{"math.isclose(1, 1.1, rel_tol=0.2)", true},
+		{"math.isclose(0, 0.0000000001)", false},
+		{"math.isclose(0, 0.001, abs_tol=0.01)", true},
+		{"math.isclose(math.inf, math.inf)", true},
+		{"math.nan == math.nan", false},
+		{"math.pi > 3.14 and math.e < 2.72", true},
+		{"math.sqrt(-1)", errorMessage("math domain error")},
+		{"math.log(0)", errorMessage("math domain error")},
+		{"math.log(8, 1)", errorMessage("math domain error")},
+		{"math.asin(2)", errorMessage("math domain error")},
+		{"math.sin(math.inf)", errorMessage("math domain error")},
+		{"math.cos(-math.inf)", errorMessage("math domain error")},
+		{"math.tan(math.inf)", errorMessage("math domain error")},
+		{"math.lcm(4611686018427387904, 3)", errorMessage("lcm() result does not fit in INTEGER")},
+		{"math.lcm(9223372036854775807, 2)", errorMessage("lcm() result does not fit in INTEGER")},
+		{"math.gcd(-9223372036854775807 - 1)", errorMessage("gcd() result does not fit in INTEGER")},
+		{"math.abs(-9223372036854775807 - 1)", errorMessage("abs() result does not fit in INTEGER")},
+		{"math.atanh(1)", errorMessage("math domain error")},
+		{"math.pow(-8, 0.5)", errorMessage("math domain error")},
+		{"math.pow(0, -1)", errorMessage("math domain error")},
+		{"math.exp(1000)", errorMessage("math range error")},
+		{"math.pow(10, 400)", errorMessage("math range error")},
+		{"math.floor(math.inf)", errorMessage("cannot convert +Inf to INTEGER")},
+		{"math.factorial(-1)", errorMessage("factorial() not defined for negative values")},
+		{"math.factorial(21)", errorMessage("factorial() result does not fit in INTEGER")},
+		{"math.isqrt(-4)", errorMessage("isqrt() argument must be non-negative")},
+		{"math.gcd(1.5)", errorMessage("gcd() argument must be INTEGER, got FLOAT")},
+		{`math.sqrt("4")`, errorMessage("sqrt() argument must be INTEGER or FLOAT, got STRING")},
+		{"math.isclose(1, 2, rel_tol=-1)", errorMessage("tolerances must be non-negative")},
+		{"math.isclose(1, 2, tol=1)", errorMessage("isclose() got an unexpected keyword argument tol")},
+	}
+	for _, tt := range tests {
+		evaluated := testEval("import math\n" + tt.input)
+		switch expected := tt.expected.(type) {
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case errorMessage:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok || errObj.Message != string(expected) {
+				t.Errorf("wrong result for %q. expected error %q, got=%+v", tt.input, expected, evaluated)
+			}
+		default:
+			checkEvalResult(t, tt.input, evaluated, tt.expected)
+		}
+	}
+}
+
+func TestReadingStdin(t *testing.T) {
+	tests := []struct {
+		input    string
+		stdin    string
+		expected interface{}
+	}{
+		{"input()", "hello\nworld\n", "hello"},
+		{"input()", "windows\r\n", "windows"},
+		{"input()", "no newline", "no newline"},
+		{"a = input()\nb = input()\na + b", "x\ny\n", "xy"},
+		{"input()", "", "EOF when reading a line"},
+		{"stdin.readline()", "one\ntwo\n", "one\n"},
+		{"stdin.readline()\nstdin.readline()", "one\n", ""},
+		{"string(stdin.readlines())", "a\nb", "[a\n, b]"},
+		{"x = input()\nstdin.read()", "a\nb\nc\n", "b\nc\n"},
+		{"n = 0\nfor line in stdin:\n    n += int(line.strip())\nn", "1\n2\n3\n", 6},
+		{"len(list(stdin))", "", 0},
+		{"stdout.readline()", "", "readline: stream stdout is not readable"},
+		{`stdin.write("x")`, "", "write: stream stdin is not writable"},
+	}
+	for _, tt := range tests {
+		interp := NewInterpreter(strings.NewReader(tt.stdin), &bytes.Buffer{}, &bytes.Buffer{})
+		checkEvalResult(t, tt.input, testEvalIn(interp, tt.input), tt.expected)
+	}
+
+	var stdout bytes.Buffer
+	interp := NewInterpreter(strings.NewReader("Ann\n"), &stdout, &bytes.Buffer{})
+	evaluated := testEvalIn(interp, `"hi " + input("name? ")`)
+	checkEvalResult(t, "input(prompt)", evaluated, "hi Ann")
+	if stdout.String() != "name? " {
+		t.Errorf("wrong prompt. got=%q", stdout.String())
+	}
+}
+
+func TestFiles(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"write_file(path, \"a\\nb\\n\")\nread_file(path)", "a\nb\n"},
+		{"f = open(path, \"w\")\nf.write(\"hi\\n\")\nf.close()\nread_file(path)", "hi\n"},
+		{"write_file(path, \"one\\ntwo\\n\")\nf = open(path)\na = f.readline()\nb = f.readline()\na + b", "one\ntwo\n"},
+		{"write_file(path, \"one\\ntwo\")\nstring(open(path).readlines())", "[one\n, two]"},
+		{"write_file(path, \"x\\ny\\n\")\nf = open(path)\nf.readline()\nf.read()", "y\n"},
+		{"write_file(path, \"1\\n2\\n3\\n\")\nn = 0\nfor line in open(path):\n    n += int(line.strip())\nn", 6},
+		{"write_file(path, \"a\")\nf = open(path, \"a\")\nf.write(\"b\")\nf.close()\nread_file(path)", "ab"},
+		{"with open(path, \"w\") as f:\n    print(\"hello\", file=f)\nread_file(path)", "hello\n"},
+		{"with open(path, \"w\") as f:\n    g = f\ng.write(\"late\")", "write: I/O operation on closed file " + filePathPlaceholder},
+		{"f = open(path, \"w\")\nf.close()\nf.close()", nil},
+		{"f = open(path, \"w\")\nf.read()", "read: file " + filePathPlaceholder + " is not open for reading"},
+		{"write_file(path, \"\")\nopen(path).write(\"x\")", "write: file " + filePathPlaceholder + " is not open for writing"},
+		{"write_file(path, \"one\\ntwo\\nthree\\n\")\nf = open(path, \"r+\")\nf.readline()\nf.write(\"XX\")\nf.close()\nread_file(path)", "one\nXXo\nthree\n"},
+		{"write_file(path, \"one\\ntwo\\n\")\nf = open(path, \"r+\")\nf.readline()\nf.write(\"T\")\nrest = f.read()\nf.close()\nrest", "wo\n"},
+		{"open(path, \"q\")", `invalid mode: "q"`},
+		{"write_file(path, \"\")\nopen(path, \"x\")", "open " + filePathPlaceholder + ": file exists"},
+		{"open(5)", "argument to `open` must be STRING, got INTEGER"},
+		{"write_file(path, 5)", "argument to `write_file` must be STRING, got INTEGER"},
+		{"type(open(path, \"w\"))", "FILE"},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "data.txt")
+		evaluated := testEvalWith(tt.input, map[string]object.Object{"path": &object.String{Value: path}})
+		expected := tt.expected
+		if str, ok := expected.(string); ok {
+			expected = strings.ReplaceAll(str, filePathPlaceholder, path)
+		}
+		checkEvalResult(t, tt.input, evaluated, expected)
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing.txt")
+	for _, input := range []string{"open(path)", "read_file(path)"} {
+		evaluated := testEvalWith(input, map[string]object.Object{"path": &object.String{Value: missing}})
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Fatalf("%s: expected an error, got=%T (%+v)", input, evaluated, evaluated)
+		}
+		if !strings.Contains(errObj.Message, missing) {
+			t.Errorf("%s: error does not mention the path. got=%q", input, errObj.Message)
+		}
+	}
+
+	dir := t.TempDir()
+	evaluated := testEvalWith(`write_file(path, "x")`,
+		map[string]object.Object{"path": &object.String{Value: filepath.Join(dir, "no", "such", "dir")}})
+	if _, ok := evaluated.(*object.Error); !ok {
+		t.Errorf("write_file into a missing directory: expected an error, got=%T", evaluated)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "no")); err == nil {
+		t.Errorf("write_file created a missing directory")
+	}
+}
+
+// filePathPlaceholder stands for the temporary file path in TestFiles.
+const filePathPlaceholder = "<path>"
+
+func TestInterpreters(t *testing.T) {
+	var out1, out2 bytes.Buffer
+	first := NewInterpreter(strings.NewReader("one\n"), &out1, &bytes.Buffer{})
+	second := NewInterpreter(strings.NewReader("two\n"), &out2, &bytes.Buffer{})
+	first.Args = []string{"first.crl"}
+
+	checkEvalResult(t, "first input", testEvalIn(first, "input()"), "one")
+	checkEvalResult(t, "second input", testEvalIn(second, "input()"), "two")
+	testEvalIn(second, `print("b")`)
+	testEvalIn(first, `print("a")`)
+	testEvalIn(first, "import sys\nsys.stdout.write(sys.argv[0])")
+	testEvalIn(second, `import sys`+"\n"+`print(len(sys.argv), file=sys.stdout)`)
+	if out1.String() != "a\nfirst.crl" || out2.String() != "b\n0\n" {
+		t.Errorf("interpreters share output. got=(%q, %q)", out1.String(), out2.String())
+	}
+
+	firstEnv := first.NewEnvironment()
+	Eval(parser.New(lexer.New("import text")).ParseProgram(), firstEnv)
+	secondEnv := second.NewEnvironment()
+	Eval(parser.New(lexer.New("import text")).ParseProgram(), secondEnv)
+	a, _ := firstEnv.Get("text")
+	b, _ := secondEnv.Get("text")
+	if a == nil || a == b {
+		t.Errorf("interpreters share imported modules. got=(%v, %v)", a, b)
+	}
+	again := first.NewEnvironment()
+	Eval(parser.New(lexer.New("import text")).ParseProgram(), again)
+	if c, _ := again.Get("text"); c != a {
+		t.Errorf("interpreter imported a module twice")
+	}
+}
+
+func TestKeywordArguments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"spell sub(a, b): return a - b\nsub(b=1, a=5)", 4},
+		{"spell sub(a, b): return a - b\nsub(5, b=2)", 3},
+		{"spell sub(a, b): return a - b\n10 |> sub(b=4)", 6},
+		{"spell sub(a, b): return a - b\nsub(1, a=2)", "multiple values for argument a"},
+		{"spell sub(a, b): return a - b\nsub(1, c=2)", "unexpected keyword argument c"},
+		{"spell sub(a, b): return a - b\nsub(b=2)", "missing argument a"},
+		{"spell sub(a, b): return a - b\nsub(1, b=2, b=3)", "keyword argument repeated: b"},
+		{"len([1], x=2)", "len() does not accept keyword arguments"},
+		{"[1].append(2, x=3)", "append() does not accept keyword arguments"},
+		{"import math\nmath.sqrt(4, x=1)", "sqrt() does not accept keyword arguments"},
+		{"input(prompt=1)", "input() does not accept keyword arguments"},
+		{"string(sorted([3, 1, 2], reverse=True))", "[3, 2, 1]"},
+		{`string(sorted(["bb", "a", "ccc"], key=len))`, "[a, bb, ccc]"},
+		{`string(sorted(["bb", "a", "ccc"], key=len, reverse=True))`, "[ccc, bb, a]"},
+		{"string(sorted([3, 1, 2], none, reverse=True))", "[3, 2, 1]"},
+		{"sorted([1], len, key=len)", "multiple values for argument key"},
+		{"sorted([1], cmp=len)", "sorted() got an unexpected keyword argument cmp"},
+	}
+	for _, tt := range tests {
+		checkEvalResult(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestMethods(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`string("a,b,c".split(","))`, "[a, b, c]"},
+		{`string(" a  b ".split())`, "[a, b]"},
+		{`"-".join(["a", "b", "c"])`, "a-b-c"},
+		{`"".join("abc")`, "abc"},
+		{`"  hi  ".strip()`, "hi"},
+		{`"xxhixx".strip("x")`, "hi"},
+		{`"a-b-c".replace("-", "+")`, "a+b+c"},
+		{`"Hello".upper()`, "HELLO"},
+		{`"Hello".lower()`, "hello"},
+		{`"hello".find("ll")`, 2},
+		{`"hello".find("z")`, -1},
+		{`"naïve café".find("café")`, 6},
+		{`s = "naïve café"` + "\n" + `s[s.find("café"):]`, "café"},
+		{`"日本語".index("語")`, 2},
+		{`s = "héllo"` + "\n" + `s[s.index("l"):len(s)]`, "llo"},
+		{`"banana".count("a")`, 3},
+		{"a = [1]\na.append(2)\na.extend((3, 4))\nstring(a)", "[1, 2, 3, 4]"},
+		{"a = [1, 2]\na.extend(a)\nstring(a)", "[1, 2, 1, 2]"},
+		{"a = [1, 2, 3]\nx = a.pop()\ny = a.pop(0)\nstring((x, y, a))", "(3, 1, [2])"},
+		{"a = [3, 1]\na.insert(1, 2)\na.reverse()\nstring(a)", "[1, 2, 3]"},
+		{"[5, 6, 7].index(6)", 1},
+		{"(1, 2, 1).count(1)", 2},
+		{`h = {"a": 1, "b": 2}` + "\nstring(h.keys())", "[a, b]"},
+		{`h = {"a": 1, "b": 2}` + "\nstring(h.values())", "[1, 2]"},
+		{`h = {"a": 1, "b": 2}` + "\nstring(h.items())", "[(a, 1), (b, 2)]"},
+		{`{"a": 1}.get("a")`, 1},
+		{`{"a": 1}.get("z", 0)`, 0},
+		{`string({"a": 1}.get("z"))`, "None"},
+		{`h = {"a": 1}` + "\n" + `h.update({"a": 3, "b": 2})` + "\nstring(h)", "{a: 3, b: 2}"},
+		{`string(dir((1, 2)))`, "[count, index]"},
+		{`string(dir(5))`, "[]"},
+		{`f = "a b".split` + "\nstring(f())", "[a, b]"},
+		{`string(["x", "y"] |> ",".join)`, "x,y"},
+		{`"abc".nope`, errorMessage("STRING has no attribute nope")},
+		{"[].append()", errorMessage("wrong number of arguments to `append`. got=0, want=1")},
+		{"[].pop(1, 2)", errorMessage("wrong number of arguments to `pop`. got=2, want=0 or 1")},
+		{`"a".split("")`, errorMessage("empty separator")},
+		{`",".join([1])`, errorMessage("sequence item 0: expected STRING, got INTEGER")},
+		{`"a".startswith(1)`, errorMessage("argument to `startswith` must be STRING, got INTEGER")},
+		{"{}.update([1])", errorMessage("argument to `update` must be HASH, got ARRAY")},
+		{"{}.get([1])", errorMessage("unusable as hash key: ARRAY")},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if expected, ok := tt.expected.(errorMessage); ok {
+			errObj, ok := evaluated.(*object.Error)
+			if !ok || errObj.Message != string(expected) {
+				t.Errorf("wrong result for %q. expected error %q, got=%+v", tt.input, expected, evaluated)
+			}
+			continue
+		}
+		checkEvalResult(t, tt.input, evaluated, tt.expected)
+	}
+
+	testBooleanObject(t, testEval(`"Hello".startswith("He")`), true)
+	testBooleanObject(t, testEval(`"Hello".endswith("x")`), false)
+}
+
+func TestInstanceMethods(t *testing.T) {
+	hooks := testHooks{
+		"greet": &object.Builtin{Fn: func(args ...object.Object) object.Object {
+			return &object.String{Value: "hi"}
+		}},
+	}
+	evaluated := testEvalWith("c.greet()", map[string]object.Object{"c": hooks})
+	checkEvalResult(t, "c.greet()", evaluated, "hi")
+	evaluated = testEvalWith("c.missing", map[string]object.Object{"c": hooks})
+	checkEvalResult(t, "c.missing", evaluated, "HOOKS has no attribute missing")
+}
+
+func TestRangesAndSlices(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"string(range(5))", "range(0, 5)"},
+		{"string(list(range(3)))", "[0, 1, 2]"},
+		{"string(list(range(2, 5)))", "[2, 3, 4]"},
+		{"string(list(range(10, 0, -3)))", "[10, 7, 4, 1]"},
+		{"len(range(0, 10, 3))", 4},
+		{"len(range(5, 0))", 0},
+		{"range(10, 20)[3]", 13},
+		{"range(10, 20)[-1]", 19},
+		{"total = 0\nfor i in range(1, 5):\n    total += i\ntotal", 10},
+		{"len(range(1000000000))", 1000000000},
+		{"string(range(10)[2:8:2])", "range(2, 8, 2)"},
+		{"string(list(range(5)[::-1]))", "[4, 3, 2, 1, 0]"},
+		{"string([1, 2, 3, 4][1:3])", "[2, 3]"},
+		{"string([1, 2, 3, 4][-2:])", "[3, 4]"},
+		{"string([1, 2, 3, 4][::-2])", "[4, 2]"},
+		{"string([1, 2, 3][5:])", "[]"},
+		{"string((1, 2, 3)[:-1])", "(1, 2)"},
+		{`"hello"[1:4]`, "ell"},
+		{`"hello"[::-1]`, "olleh"},
+		{"[1, 2][::0]", errorMessage("slice step cannot be zero")},
+		{`[1, 2]["a":]`, errorMessage("slice indices must be INTEGER or none, got STRING")},
+		{"5[1:]", errorMessage("slice operator not supported: INTEGER")},
+		{"range(1, 2, 0)", errorMessage("range() step must not be zero")},
+		{"range(1.5)", errorMessage("range() arguments must be INTEGER, got FLOAT")},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if expected, ok := tt.expected.(errorMessage); ok {
+			errObj, ok := evaluated.(*object.Error)
+			if !ok || errObj.Message != string(expected) {
+				t.Errorf("wrong result for %q. expected error %q, got=%+v", tt.input, expected, evaluated)
+			}
+			continue
+		}
+		checkEvalResult(t, tt.input, evaluated, tt.expected)
+	}
+
+	boolTests := []struct {
+		input    string
+		expected bool
+	}{
+		{"5 in range(0, 10, 5)", true},
+		{"7 in range(0, 10, 5)", false},
+		{"2.0 in range(3)", true},
+		{`"a" in range(3)`, false},
+		{"range(0) == range(5, 5)", true},
+		{"range(3) == range(0, 3, 1)", true},
+		{"range(3) == range(1, 3)", false},
+		{"!range(0)", true},
+	}
+	for _, tt := range boolTests {
+		testBooleanObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestFunctionalBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"spell double(x): return x * 2\nstring(list(map(double, [1, 2, 3])))", "[2, 4, 6]"},
+		{"spell double(x): return x * 2\nstring(list([1, 2] |> map(double)))", "[2, 4]"},
+		{`string(list(map(len, ["a", "bcd"])))`, "[1, 3]"},
+		{"spell even(x): return x % 2 == 0\nstring(list(filter(even, [1, 2, 3, 4])))", "[2, 4]"},
+		{`string(list(filter(none, [0, 1, "", "a"])))`, "[1, a]"},
+		{"spell add(a, b): return a + b\nreduce(add, [1, 2, 3])", 6},
+		{"spell add(a, b): return a + b\nreduce(add, [], 10)", 10},
+		{"spell add(a, b): return a + b\n[1, 2, 3] |> reduce(add, 10)", 16},
+		{"spell add(a, b): return a + b\n[1, 2, 3] |> reduce(add)", 6},
+		{`string(list([0, 1, "", "a"] |> filter(none)))`, "[1, a]"},
+		{"spell add(a, b): return a + b\ninc = partial(add, 1)\ninc(41)", 42},
+		{"spell sub(a, b, c): return a - b - c\npartial(sub, 10, 3)(2)", 5},
+		{"spell inc(x): return x + 1\nspell double(x): return x * 2\ncompose(inc, double)(5)", 11},
+		{"spell inc(x): return x + 1\nf = compose(inc)\nf(1)", 2},
+		{"calls = []\nspell sq(x):\n    append(calls, x)\n    return x * x\nf = memoize(sq)\nr = [f(3), f(3), f(4)]\nsum(r) + len(calls)", 36},
+		{"calls = []\nspell size(a):\n    append(calls, a)\n    return len(a)\nf = memoize(size)\nr = [f([1]), f([1])]\nsum(r) + len(calls)", 4},
+		{"spell nums():\n    yield 1\n    yield 2\nspell inc(x): return x + 1\nsum(map(inc, nums()))", 5},
+		{"spell bad(x): return x + True\nlist(map(bad, [1]))", errorMessage("type mismatch: INTEGER + BOOLEAN")},
+		{"map(1, [1])", errorMessage("argument to `map` must be a spell, got INTEGER")},
+		{"spell double(x): return x * 2\nmap([1], double)", errorMessage("argument to `map` must be a spell, got ARRAY")},
+		{"filter(len, 5)", errorMessage("argument to `filter` must be iterable, got INTEGER")},
+		{"spell add(a, b): return a + b\nreduce(add, [])", errorMessage("reduce() of empty sequence with no initial value")},
+		{"partial(1)", errorMessage("argument to `partial` must be a spell, got INTEGER")},
+		{"compose()", errorMessage("wrong number of arguments. got=0, want at least 1")},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if expected, ok := tt.expected.(errorMessage); ok {
+			errObj, ok := evaluated.(*object.Error)
+			if !ok || errObj.Message != string(expected) {
+				t.Errorf("wrong result for %q. expected error %q, got=%+v", tt.input, expected, evaluated)
+			}
+			continue
+		}
+		checkEvalResult(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestPipelineExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"spell inc(x): return x + 1\n5 |> inc", 6},
+		{"spell inc(x): return x + 1\n5 |> inc |> inc |> inc", 8},
+		{"spell sub(a, b): return a - b\n10 |> sub(3)", 7},
+		{"spell sub(a, b): return a - b\nspell inc(x): return x + 1\n1 + 9 |> sub(3) |> inc", 8},
+		{`"abc" |> len`, 3},
+		{"5 |> missing", "identifier not found: missing"},
+		{"5 |> 3", "not a function: INTEGER"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		}
+	}
+}
+
+func TestBitwiseAndArithmeticOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"6 & 3", 2},
+		{"6 | 3", 7},
+		{"6 ^ 3", 5},
+		{"~5", -6},
+		{"1 << 4", 16},
+		{"256 >> 4", 16},
+		{"-16 >> 2", -4},
+		{"2 ** 10", 1024},
+		{"2 ** 3 ** 2", 512},
+		{"-2 ** 2", -4},
+		{"7 ** 0", 1},
+		{"7 // 2", 3},
+		{"-7 // 2", -4},
+		{"7 // -2", -4},
+		{"-7 // -2", 3},
+		{"7 % 2", 1},
+		{"-7 % 2", 1},
+		{"7 % -2", -1},
+		{"-7 % -2", -1},
+		{"-6 % 3", 0},
+		{"a = -7\nb = 2\n(a // b) * b + a % b == a", true},
+		{"2 ** -1", 0.5},
+		{"2.0 ** 3", 8.0},
+		{"4 ** 0.5", 2.0},
+		{"7.5 // 2", 3.0},
+		{"-7.5 // 2", -4.0},
+		{"1 + 0.5", 1.5},
+		{"1 / 0", "division by zero"},
+		{"1 // 0", "division by zero"},
+		{"1 % 0", "division by zero"},
+		{"1 << -1", "negative shift count: -1"},
+		{"~1.5", "unknown operator: ~FLOAT"},
+		{"1.5 & 1.5", "unknown operator: FLOAT & FLOAT"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("%s: object is not Error. got=%T (%+v)", tt.input, evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		}
+	}
+}
+
+func testFloatObject(t *testing.T, obj object.Object, expected float64) bool {
+	result, ok := obj.(*object.Float)
+	if !ok {
+		t.Errorf("object is not Float. got=%T (%+v)", obj, obj)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%f, wanted=%f", result.Value, expected)
+		return false
+	}
+	return true
+}
+
+func TestCompoundAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"x = 5\nx += 2\nx", 7},
+		{"x = 5\nx -= 2\nx", 3},
+		{"x = 5\nx *= 2\nx", 10},
+		{"x = 5\nx /= 2\nx", 2},
+		{"x = 3\nx **= 3\nx", 27},
+		{"x = -7\nx //= 2\nx", -4},
+		{"x = 6\nx &= 3\nx", 2},
+		{"x = 6\nx |= 3\nx", 7},
+		{"x = 6\nx ^= 3\nx", 5},
+		{"x = 1\nx <<= 3\nx", 8},
+		{"x = 8\nx >>= 3\nx", 1},
+	}
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+
+	evaluated := testEval("y += 1")
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+	if errObj.Message != "identifier not found: y" {
+		t.Errorf("wrong error message. got=%q", errObj.Message)
+	}
+}
+
+// testVector stands in for a spellbook instance: a one-dimensional vector
+// whose special spells are builtins bound to it.
+type testVector struct {
+	x       int64
+	methods map[string]object.Object
+}
+
+func newTestVector(x int64) *testVector {
+	v := &testVector{x: x}
+	method := func(fn func(args ...object.Object) object.Object) object.Object {
+		return &object.Builtin{Fn: fn}
+	}
+	other := func(obj object.Object) int64 {
+		switch obj := obj.(type) {
+		case *testVector:
+			return obj.x
+		case *object.Integer:
+			return obj.Value
+		}
+		return 0
+	}
+	v.methods = map[string]object.Object{
+		"__add__": method(func(args ...object.Object) object.Object {
+			return newTestVector(v.x + other(args[0]))
+		}),
+		"__radd__": method(func(args ...object.Object) object.Object {
+			return newTestVector(other(args[0]) + v.x)
+		}),
+		"__eq__": method(func(args ...object.Object) object.Object {
+			return nativeBoolToBooleanObject(v.x == other(args[0]))
+		}),
+		"__lt__": method(func(args ...object.Object) object.Object {
+			return nativeBoolToBooleanObject(v.x < other(args[0]))
+		}),
+		"__neg__": method(func(args ...object.Object) object.Object {
+			return newTestVector(-v.x)
+		}),
+		"__len__": method(func(args ...object.Object) object.Object {
+			return &object.Integer{Value: v.x}
+		}),
+		"__getitem__": method(func(args ...object.Object) object.Object {
+			return &object.Integer{Value: v.x * other(args[0])}
+		}),
+		"__contains__": method(func(args ...object.Object) object.Object {
+			return nativeBoolToBooleanObject(other(args[0]) <= v.x)
+		}),
+		"__str__": method(func(args ...object.Object) object.Object {
+			return &object.String{Value: fmt.Sprintf("Vector(%d)", v.x)}
+		}),
+		"__iter__": method(func(args ...object.Object) object.Object {
+			elements := []object.Object{}
+			for i := int64(0); i < v.x; i++ {
+				elements = append(elements, &object.Integer{Value: i})
+			}
+			return &object.Array{Elements: elements}
+		}),
+	}
+	return v
+}
+
+func (v *testVector) Type() object.ObjectType { return "VECTOR" }
+func (v *testVector) Inspect() string         { return "<vector>" }
+func (v *testVector) Method(name string) (object.Object, bool) {
+	method, ok := v.methods[name]
+	return method, ok
+}
+
+func TestOperatorHooks(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"string(v + 2)", "Vector(5)"},
+		{"string(2 + v)", "Vector(5)"},
+		{"string(v + v)", "Vector(6)"},
+		{"string(-v)", "Vector(-3)"},
+		{"string(v)", "Vector(3)"},
+		{"v == 3", true},
+		{"v != 3", false},
+		{"3 == v", true},
+		{"v < 4", true},
+		{"4 > v", true},
+		{"len(v)", 3},
+		{"v[5]", 15},
+		{"2 in v", true},
+		{"9 not in v", true},
+		{"string(list(v))", "[0, 1, 2]"},
+		{"total = 0\nfor i in v:\n    total += i\ntotal", 3},
+		{"1 if v else 2", 1},
+		{"v * 2", "type mismatch: VECTOR * INTEGER"},
+		{"v is v", true},
+	}
+	for _, tt := range tests {
+		evaluated := testEvalWith(tt.input, map[string]object.Object{"v": newTestVector(3)})
+		if expected, ok := tt.expected.(bool); ok {
+			testBooleanObject(t, evaluated, expected)
+			continue
+		}
+		checkEvalResult(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestTruthinessHookErrors(t *testing.T) {
+	failing := &object.Builtin{Fn: func(args ...object.Object) object.Object {
+		return newError("broken hook")
+	}}
+	inputs := []string{
+		"if h:\n    1",
+		"if False:\n    1\notherwise h:\n    2",
+		"1 if h else 2",
+		"h and 1",
+		"h or 1",
+		"!h",
+		"assert h",
+		"any([h])",
+		"all([h])",
+		"list(filter(none, [h]))",
+		"match 1:\n    case 1 if h:\n        2",
+	}
+	for _, hook := range []string{"__bool__", "__len__"} {
+		globals := map[string]object.Object{"h": testHooks{hook: failing}}
+		for _, input := range inputs {
+			checkEvalResult(t, hook+": "+input, testEvalWith(input, globals), "broken hook")
+		}
+	}
+}
+
+// testResource is a builtin-style context manager that records its calls.
+type testResource struct {
+	log      []string
+	suppress bool
+}
+
+func (r *testResource) Type() object.ObjectType { return "RESOURCE" }
+func (r *testResource) Inspect() string         { return "<resource>" }
+func (r *testResource) Enter() object.Object {
+	r.log = append(r.log, "enter")
+	return &object.String{Value: "handle"}
+}
+func (r *testResource) Exit(err *object.Error) (bool, *object.Error) {
+	if err != nil {
+		r.log = append(r.log, "exit: "+err.Message)
+	} else {
+		r.log = append(r.log, "exit")
+	}
+	return r.suppress, nil
+}
+
+// testHooks is an instance made only of the given special spells.
+type testHooks map[string]object.Object
+
+func (h testHooks) Type() object.ObjectType { return "HOOKS" }
+func (h testHooks) Inspect() string         { return "<hooks>" }
+func (h testHooks) Method(name string) (object.Object, bool) {
+	method, ok := h[name]
+	return method, ok
+}
+
+func TestWithStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		suppress bool
+		expected interface{}
+		log      []string
+	}{
+		{"with r as h:\n    x = h\nx", false, "handle", []string{"enter", "exit"}},
+		{"with r: 1", false, 1, []string{"enter", "exit"}},
+		{"spell f():\n    with r:\n        return 2\n    return 3\nf()", false, 2, []string{"enter", "exit"}},
+		{"with r:\n    missing\n5", false, "identifier not found: missing",
+			[]string{"enter", "exit: identifier not found: missing"}},
+		{"with r:\n    missing\n5", true, 5, []string{"enter", "exit: identifier not found: missing"}},
+		{"with 5: 1", false, "INTEGER does not support the with statement", nil},
+		{"spell gen():\n    with r:\n        yield 1\n        yield 2\nfor x in gen():\n    ignore", false, nil, []string{"enter", "exit"}},
+	}
+	for _, tt := range tests {
+		resource := &testResource{suppress: tt.suppress}
+		evaluated := testEvalWith(tt.input, map[string]object.Object{"r": resource})
+		checkEvalResult(t, tt.input, evaluated, tt.expected)
+		if tt.log != nil && fmt.Sprint(resource.log) != fmt.Sprint(tt.log) {
+			t.Errorf("wrong calls for %q. expected=%v, got=%v", tt.input, tt.log, resource.log)
+		}
+	}
+}
+
+func TestWithStatementInstanceHooks(t *testing.T) {
+	var exited object.Object
+	hooks := testHooks{
+		"__enter__": &object.Builtin{Fn: func(args ...object.Object) object.Object {
+			return &object.Integer{Value: 7}
+		}},
+		"__exit__": &object.Builtin{Fn: func(args ...object.Object) object.Object {
+			exited = args[0]
+			return nativeBoolToBooleanObject(args[0] != NONE)
+		}},
+	}
+
+	evaluated := testEvalWith("with c as n:\n    y = n * 2\ny", map[string]object.Object{"c": hooks})
+	testIntegerObject(t, evaluated, 14)
+	testNoneObject(t, exited)
+
+	evaluated = testEvalWith("with c:\n    1 + True\n3", map[string]object.Object{"c": hooks})
+	testIntegerObject(t, evaluated, 3)
+	if errObj, ok := exited.(*object.Error); !ok || errObj.Message != "type mismatch: INTEGER + BOOLEAN" {
+		t.Errorf("__exit__ got wrong error. got=%+v", exited)
+	}
+
+	noExit := testHooks{"__enter__": hooks["__enter__"]}
+	evaluated = testEvalWith("with c: 1", map[string]object.Object{"c": noExit})
+	checkEvalResult(t, "with c: 1", evaluated, "HOOKS does not support the with statement")
+}
+
+func TestLocks(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"l = lock()\nl.locked()", false},
+		{"l = lock()\nwith l:\n    held = l.locked()\nheld", true},
+		{"l = lock()\nwith l:\n    ignore\nl.locked()", false},
+		{"l = lock()\nwith l as m:\n    same = m is l\nsame", true},
+		{"l = lock()\nspell f():\n    with l:\n        return 1\nf()\nl.locked()", false},
+		{"l = lock()\nl.acquire()\nl.locked()", true},
+		{"l = lock()\nl.acquire()\nl.release()\nl.locked()", false},
+		{"l = lock()\nl.acquire()\nl.acquire()", "lock is already held"},
+		{"l = lock()\nwith l:\n    with l:\n        ignore", "lock is already held"},
+		{"lock().release()", "release of unlocked lock"},
+		{"l = lock()\nwith l:\n    l.release()", "release of unlocked lock"},
+		{"type(lock())", "LOCK"},
+		{"lock(1)", "wrong number of arguments. got=1, want=0"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if expected, ok := tt.expected.(bool); ok {
+			testBooleanObject(t, evaluated, expected)
+			continue
+		}
+		checkEvalResult(t, tt.input, evaluated, tt.expected)
+	}
+
+	l := &object.Lock{}
+	evaluated := testEvalWith("with l:\n    missing", map[string]object.Object{"l": l})
+	checkEvalResult(t, "with l: missing", evaluated, "identifier not found: missing")
+	if l.Locked() {
+		t.Errorf("lock still held after the body failed")
+	}
+}
+
+// testEvalIn evaluates input in a new global environment of interp.
+func testEvalIn(interp *Interpreter, input string) object.Object {
+	p := parser.New(lexer.New(input))
+	return Eval(p.ParseProgram(), interp.NewEnvironment())
+}
+
+func testEvalWith(input string, globals map[string]object.Object) object.Object {
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	env := object.NewEnvironment()
+	for name, val := range globals {
+		env.Set(name, val)
+	}
+	return Eval(program, env)
+}
+
+func checkEvalResult(t *testing.T, input string, evaluated object.Object, expected interface{}) {
+	t.Helper()
+	switch expected := expected.(type) {
+	case int:
+		testIntegerObject(t, evaluated, int64(expected))
+	case string:
+		switch obj := evaluated.(type) {
+		case *object.String:
+			if obj.Value != expected {
+				t.Errorf("wrong value for %q. expected=%q, got=%q", input, expected, obj.Value)
+			}
+		case *object.Error:
+			if obj.Message != expected {
+				t.Errorf("wrong error for %q. expected=%q, got=%q", input, expected, obj.Message)
+			}
+		default:
+			t.Errorf("unexpected result for %q. got=%T (%+v)", input, evaluated, evaluated)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package evaluator
+
+import (
+	"os"
+
+	"thecarrionlanguage/object"
+)
+
+// openBuiltin opens a file in the optional mode, which defaults to "r".
+func openBuiltin(args ...object.Object) object.Object {
+	if len(args) != 1 && len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+	path, err := stringArgument("open", args[0])
+	if err != nil {
+		return err
+	}
+	mode := "r"
+	if len(args) == 2 {
+		modeArg, err := stringArgument("open", args[1])
+		if err != nil {
+			return err
+		}
+		mode = modeArg.Value
+	}
+	file, openErr := object.OpenFile(path.Value, mode)
+	if openErr != nil {
+		return newError("%s", openErr)
+	}
+	return file
+}
+
+func fileClose(receiver object.Object, args ...object.Object) object.Object {
+	if err := receiver.(*object.File).Close(); err != nil {
+		return newError("%s", err)
+	}
+	return NONE
+}
+
+// readFileBuiltin returns the whole contents of a file.
+func readFileBuiltin(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+	path, err := stringArgument("read_file", args[0])
+	if err != nil {
+		return err
+	}
+	data, readErr := os.ReadFile(path.Value)
+	if readErr != nil {
+		return newError("%s", readErr)
+	}
+	return &object.String{Value: string(data)}
+}
+
+// writeFileBuiltin replaces the contents of a file, creating it if needed.
+func writeFileBuiltin(args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+	path, err := stringArgument("write_file", args[0])
+	if err != nil {
+		return err
+	}
+	content, err := stringArgument("write_file", args[1])
+	if err != nil {
+		return err
+	}
+	if writeErr := os.WriteFile(path.Value, []byte(content.Value), 0o644); writeErr != nil {
+		return newError("%s", writeErr)
+	}
+	return NONE
+}
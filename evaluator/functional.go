@@ -0,0 +1,197 @@
+package evaluator
+
+import (
+	"thecarrionlanguage/object"
+)
+
+func isCallable(obj object.Object) bool {
+	switch obj.(type) {
+	case *object.Function, *object.Builtin:
+		return true
+	}
+	return false
+}
+
+// spellAndIterable checks the spell and iterable arguments of map, filter and
+// reduce. A none spell is allowed where allowNone is set.
+func spellAndIterable(name string, fn, iterable object.Object, allowNone bool) (object.Object, object.Object, *object.Error) {
+	if !isCallable(fn) && !(allowNone && fn == NONE) {
+		return nil, nil, newError("argument to `%s` must be a spell, got %s", name, fn.Type())
+	}
+	if !isIterable(iterable) {
+		return nil, nil, newError("argument to `%s` must be iterable, got %s", name, iterable.Type())
+	}
+	return fn, iterable, nil
+}
+
+// mapBuiltin lazily applies a spell to each element.
+func mapBuiltin(args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+	fn, iterable, err := spellAndIterable("map", args[0], args[1], false)
+	if err != nil {
+		return err
+	}
+	iterator, errObj := iterate(iterable)
+	if errObj != nil {
+		return errObj
+	}
+	return object.NewGenerator(func(yield func(object.Object) bool) {
+		for {
+			item, ok := iterator.Next()
+			if !ok {
+				return
+			}
+			if !isError(item) {
+				item = callSpell(fn, []object.Object{item})
+			}
+			if isError(item) {
+				stopIterator(iterator)
+				yield(item)
+				return
+			}
+			if !yield(item) {
+				stopIterator(iterator)
+				return
+			}
+		}
+	})
+}
+
+// filterBuiltin lazily keeps the elements for which the spell returns a true
+// value. With none as the spell the elements themselves are tested.
+func filterBuiltin(args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+	fn, iterable, err := spellAndIterable("filter", args[0], args[1], true)
+	if err != nil {
+		return err
+	}
+	iterator, errObj := iterate(iterable)
+	if errObj != nil {
+		return errObj
+	}
+	return object.NewGenerator(func(yield func(object.Object) bool) {
+		for {
+			item, ok := iterator.Next()
+			if !ok {
+				return
+			}
+			test := item
+			if !isError(item) && fn != NONE {
+				test = callSpell(fn, []object.Object{item})
+			}
+			if isError(test) {
+				stopIterator(iterator)
+				yield(test)
+				return
+			}
+			truthy, err := isTruthy(test)
+			if err != nil {
+				stopIterator(iterator)
+				yield(err)
+				return
+			}
+			if truthy && !yield(item) {
+				stopIterator(iterator)
+				return
+			}
+		}
+	})
+}
+
+// reduceBuiltin folds the elements from the left with a two-argument spell,
+// starting from the optional initial value or else the first element.
+func reduceBuiltin(args ...object.Object) object.Object {
+	if len(args) != 2 && len(args) != 3 {
+		return newError("wrong number of arguments. got=%d, want=2 or 3", len(args))
+	}
+	fn, iterable, err := spellAndIterable("reduce", args[0], args[1], false)
+	if err != nil {
+		return err
+	}
+	elements, errObj := collect(iterable)
+	if errObj != nil {
+		return errObj
+	}
+
+	var acc object.Object
+	if len(args) == 3 {
+		acc = args[2]
+	} else {
+		if len(elements) == 0 {
+			return newError("reduce() of empty sequence with no initial value")
+		}
+		acc, elements = elements[0], elements[1:]
+	}
+	for _, el := range elements {
+		acc = callSpell(fn, []object.Object{acc, el})
+		if isError(acc) {
+			return acc
+		}
+	}
+	return acc
+}
+
+// partialBuiltin fixes the leading arguments of a spell.
+func partialBuiltin(args ...object.Object) object.Object {
+	if len(args) == 0 {
+		return newError("wrong number of arguments. got=0, want at least 1")
+	}
+	fn, bound := args[0], args[1:]
+	if !isCallable(fn) {
+		return newError("argument to `partial` must be a spell, got %s", fn.Type())
+	}
+	return &object.Builtin{Fn: func(rest ...object.Object) object.Object {
+		callArgs := append(append([]object.Object{}, bound...), rest...)
+		return callSpell(fn, callArgs)
+	}}
+}
+
+// composeBuiltin chains spells right to left: compose(f, g)(x) is f(g(x)).
+func composeBuiltin(args ...object.Object) object.Object {
+	if len(args) == 0 {
+		return newError("wrong number of arguments. got=0, want at least 1")
+	}
+	for _, fn := range args {
+		if !isCallable(fn) {
+			return newError("argument to `compose` must be a spell, got %s", fn.Type())
+		}
+	}
+	fns := append([]object.Object{}, args...)
+	return &object.Builtin{Fn: func(callArgs ...object.Object) object.Object {
+		result := callSpell(fns[len(fns)-1], callArgs)
+		for i := len(fns) - 2; i >= 0 && !isError(result); i-- {
+			result = callSpell(fns[i], []object.Object{result})
+		}
+		return result
+	}}
+}
+
+// memoizeBuiltin caches a spell's results by its arguments. Calls with an
+// unhashable argument, and calls that fail, are not cached.
+func memoizeBuiltin(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+	fn := args[0]
+	if !isCallable(fn) {
+		return newError("argument to `memoize` must be a spell, got %s", fn.Type())
+	}
+	cache := make(map[object.HashKey]object.Object)
+	return &object.Builtin{Fn: func(callArgs ...object.Object) object.Object {
+		key, hashable := object.HashKeyOf(&object.Tuple{Elements: callArgs})
+		if hashable {
+			if result, ok := cache[key]; ok {
+				return result
+			}
+		}
+		result := callSpell(fn, callArgs)
+		if hashable && !isError(result) {
+			cache[key] = result
+		}
+		return result
+	}}
+}
@@ -0,0 +1,139 @@
+package evaluator
+
+import (
+	"thecarrionlanguage/ast"
+	"thecarrionlanguage/object"
+)
+
+// containsYield reports whether a spell body yields, which makes calling the
+// spell return a generator. Nested spell definitions are separate generators
+// and are not searched.
+func containsYield(node ast.Node) bool {
+	switch node := node.(type) {
+	case *ast.YieldStatement:
+		return true
+	case *ast.BlockStatement:
+		if node == nil {
+			return false
+		}
+		for _, stmt := range node.Statements {
+			if containsYield(stmt) {
+				return true
+			}
+		}
+	case *ast.IfStatement:
+		if containsYield(node.Consequence) || containsYield(node.Alternative) {
+			return true
+		}
+		for _, branch := range node.OtherwiseBranches {
+			if containsYield(branch.Consequence) {
+				return true
+			}
+		}
+	case *ast.ForStatement:
+		return containsYield(node.Body) || containsYield(node.Alternative)
+	case *ast.WithStatement:
+		return containsYield(node.Body)
+	case *ast.MatchStatement:
+		for _, clause := range node.Cases {
+			if containsYield(clause.Body) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func newGenerator(fn *object.Function, args []object.Object) *object.Generator {
+	return object.NewGenerator(func(yield func(object.Object) bool) {
+		stopped := false
+		env := extendFunctionEnv(fn, args)
+		env.SetYield(func(val object.Object) bool {
+			if !yield(val) {
+				stopped = true
+			}
+			return !stopped
+		})
+
+		// Errors end the generator and are handed to whoever is consuming it
+		result := Eval(fn.Body, env)
+		if isError(result) && !stopped {
+			yield(result)
+		}
+	})
+}
+
+func evalYieldStatement(ys *ast.YieldStatement, env *object.Environment) object.Object {
+	var val object.Object = NONE
+	if ys.Value != nil {
+		val = Eval(ys.Value, env)
+		if isError(val) {
+			return val
+		}
+	}
+
+	yield, ok := env.Yield()
+	if !ok {
+		return newError("yield outside of a spell")
+	}
+	if !yield(val) {
+		// The consumer has abandoned the generator; unwind the body like a return
+		return &object.ReturnValue{Value: NONE}
+	}
+	return NONE
+}
+
+// isIterable reports whether obj can be looped over: a builtin iterable or an
+// instance with __iter__.
+func isIterable(obj object.Object) bool {
+	if _, ok := obj.(object.Iterable); ok {
+		return true
+	}
+	if instance, ok := obj.(object.Instance); ok {
+		_, ok = instance.Method("__iter__")
+		return ok
+	}
+	return false
+}
+
+// iterate returns an iterator over obj, or an error when obj can't be looped over.
+func iterate(obj object.Object) (object.Iterator, object.Object) {
+	if result, ok := callHook(obj, "__iter__"); ok {
+		if isError(result) {
+			return nil, result
+		}
+		obj = result
+	}
+	iterable, ok := obj.(object.Iterable)
+	if !ok {
+		return nil, newError("%s is not iterable", obj.Type())
+	}
+	return iterable.Iter(), nil
+}
+
+// stopIterator releases an iterator that is abandoned before it is
+// exhausted, so a suspended generator body can unwind.
+func stopIterator(iterator object.Iterator) {
+	if gen, ok := iterator.(*object.Generator); ok {
+		gen.Stop()
+	}
+}
+
+// collect drains an iterable into a slice, stopping at the first error.
+func collect(obj object.Object) ([]object.Object, object.Object) {
+	iter, err := iterate(obj)
+	if err != nil {
+		return nil, err
+	}
+	elements := []object.Object{}
+	for {
+		elem, ok := iter.Next()
+		if !ok {
+			return elements, nil
+		}
+		if isError(elem) {
+			return nil, elem
+		}
+		elements = append(elements, elem)
+	}
+}
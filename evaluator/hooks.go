@@ -0,0 +1,121 @@
+package evaluator
+
+import (
+	"thecarrionlanguage/object"
+)
+
+// callSpell is applyFunction. It is assigned in init because builtins that
+// call back into user spells would otherwise form an initialization cycle
+// through evalIdentifier's use of the builtins table.
+var callSpell func(fn object.Object, args []object.Object) object.Object
+
+func init() {
+	callSpell = applyFunction
+}
+
+// binaryHooks name the special spell consulted on the left operand of an
+// infix operator. `in` is the exception: it asks the right operand.
+var binaryHooks = map[string]string{
+	"+":  "__add__",
+	"-":  "__sub__",
+	"*":  "__mul__",
+	"/":  "__div__",
+	"//": "__floordiv__",
+	"%":  "__mod__",
+	"**": "__pow__",
+	"&":  "__and__",
+	"|":  "__or__",
+	"^":  "__xor__",
+	"<<": "__lshift__",
+	">>": "__rshift__",
+	"==": "__eq__",
+	"!=": "__ne__",
+	"<":  "__lt__",
+	">":  "__gt__",
+	"<=": "__le__",
+	">=": "__ge__",
+	"in": "__contains__",
+}
+
+// reflectedHooks are tried on the right operand when the left one has no
+// hook, so that `2 * vector` works as well as `vector * 2`.
+var reflectedHooks = map[string]string{
+	"+":  "__radd__",
+	"-":  "__rsub__",
+	"*":  "__rmul__",
+	"/":  "__rdiv__",
+	"//": "__rfloordiv__",
+	"%":  "__rmod__",
+	"**": "__rpow__",
+	"&":  "__rand__",
+	"|":  "__ror__",
+	"^":  "__rxor__",
+	"<<": "__rlshift__",
+	">>": "__rrshift__",
+	"==": "__eq__",
+	"!=": "__ne__",
+	"<":  "__gt__",
+	">":  "__lt__",
+	"<=": "__ge__",
+	">=": "__le__",
+}
+
+var prefixHooks = map[string]string{
+	"-": "__neg__",
+	"~": "__invert__",
+}
+
+// callHook calls the special spell name on obj if obj is an instance that
+// defines it.
+func callHook(obj object.Object, name string, args ...object.Object) (object.Object, bool) {
+	instance, ok := obj.(object.Instance)
+	if !ok {
+		return nil, false
+	}
+	method, ok := instance.Method(name)
+	if !ok {
+		return nil, false
+	}
+	return callSpell(method, args), true
+}
+
+// callBinaryHook dispatches an infix operator to a special spell on either
+// operand. `not in` and a missing `__ne__` fall back to negating `__contains__`
+// and `__eq__`.
+func callBinaryHook(operator string, left, right object.Object) (object.Object, bool) {
+	switch operator {
+	case "in":
+		return callHook(right, binaryHooks["in"], left)
+	case "not in":
+		return negateHook(callHook(right, binaryHooks["in"], left))
+	}
+
+	name, ok := binaryHooks[operator]
+	if !ok {
+		return nil, false
+	}
+	if result, ok := callHook(left, name, right); ok {
+		return result, true
+	}
+	if result, ok := callHook(right, reflectedHooks[operator], left); ok {
+		return result, true
+	}
+	if operator == "!=" {
+		if result, ok := negateHook(callHook(left, "__eq__", right)); ok {
+			return result, true
+		}
+		return negateHook(callHook(right, "__eq__", left))
+	}
+	return nil, false
+}
+
+func negateHook(result object.Object, ok bool) (object.Object, bool) {
+	if !ok || isError(result) {
+		return result, ok
+	}
+	truthy, err := isTruthy(result)
+	if err != nil {
+		return err, true
+	}
+	return nativeBoolToBooleanObject(!truthy), true
+}
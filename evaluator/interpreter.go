@@ -0,0 +1,84 @@
+package evaluator
+
+import (
+	"bufio"
+	"io"
+	"os"
+
+	"thecarrionlanguage/object"
+)
+
+// Interpreter holds the state of one running program: the streams it reads
+// and writes, its command-line arguments and the modules it has imported.
+// Every global environment belongs to one interpreter, so that several can
+// run side by side without sharing output or modules.
+type Interpreter struct {
+	// Args holds the script path and the arguments after it, for sys.argv.
+	Args []string
+	// AssertionsEnabled controls whether assert statements are checked. The
+	// -no-assert flag turns it off.
+	AssertionsEnabled bool
+
+	// input() and the stdin stream share one buffered reader so that
+	// neither loses input the other has buffered.
+	stdin, stdout, stderr *object.Stream
+	print, input          *object.Builtin
+
+	modules   map[string]*object.Module // Imported modules, loaded at most once
+	importing map[string]bool
+}
+
+// NewInterpreter returns an interpreter whose input() and stdin stream read
+// from stdin, and whose print and stdout and stderr streams write to stdout
+// and stderr.
+func NewInterpreter(stdin io.Reader, stdout, stderr io.Writer) *Interpreter {
+	interp := &Interpreter{
+		stdin:     &object.Stream{Name: "stdin", Reader: bufio.NewReader(stdin)},
+		stdout:    &object.Stream{Name: "stdout", Writer: stdout},
+		stderr:    &object.Stream{Name: "stderr", Writer: stderr},
+		modules:   map[string]*object.Module{},
+		importing: map[string]bool{},
+
+		AssertionsEnabled: true,
+	}
+	interp.print = &object.Builtin{Name: "print", KwFn: interp.printBuiltin}
+	interp.input = &object.Builtin{Name: "input", Fn: interp.inputBuiltin}
+	return interp
+}
+
+// NewEnvironment returns a global environment whose programs run in interp.
+func (interp *Interpreter) NewEnvironment() *object.Environment {
+	env := object.NewEnvironment()
+	env.SetHost(interp)
+	return env
+}
+
+// interpreterOf returns the interpreter that env belongs to. A global
+// environment created without one gets its own interpreter on the process's
+// standard streams.
+func interpreterOf(env *object.Environment) *Interpreter {
+	if interp, ok := env.Host().(*Interpreter); ok {
+		return interp
+	}
+	interp := NewInterpreter(os.Stdin, os.Stdout, os.Stderr)
+	env.Global().SetHost(interp)
+	return interp
+}
+
+// lookup resolves the predefined names whose values belong to the
+// interpreter: print, input and the standard streams.
+func (interp *Interpreter) lookup(name string) (object.Object, bool) {
+	switch name {
+	case "print":
+		return interp.print, true
+	case "input":
+		return interp.input, true
+	case "stdin":
+		return interp.stdin, true
+	case "stdout":
+		return interp.stdout, true
+	case "stderr":
+		return interp.stderr, true
+	}
+	return nil, false
+}
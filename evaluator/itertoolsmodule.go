@@ -0,0 +1,38 @@
+package evaluator
+
+import "thecarrionlanguage/object"
+
+// itertoolsModule holds the Go members of the `itertools` module; the rest
+// of it is written in Carrion in munin/itertools.crl.
+func itertoolsModule() map[string]object.Object {
+	return map[string]object.Object{
+		"chunk": &object.Builtin{Fn: itertoolsChunk},
+	}
+}
+
+// itertoolsChunk splits an iterable into lists of size elements; the last
+// list holds whatever is left over.
+func itertoolsChunk(args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+	size, err := integerArgument("chunk", args[1])
+	if err != nil {
+		return err
+	}
+	if size <= 0 {
+		return newError("chunk size must be positive, got %d", size)
+	}
+	elements, errObj := collectArgument("chunk", args[0])
+	if errObj != nil {
+		return errObj
+	}
+	chunks := []object.Object{}
+	for start := 0; start < len(elements); start += int(size) {
+		end := min(start+int(size), len(elements))
+		chunk := make([]object.Object, end-start)
+		copy(chunk, elements[start:end])
+		chunks = append(chunks, &object.Array{Elements: chunk})
+	}
+	return &object.Array{Elements: chunks}
+}
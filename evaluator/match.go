@@ -0,0 +1,210 @@
+package evaluator
+
+import (
+	"thecarrionlanguage/ast"
+	"thecarrionlanguage/object"
+)
+
+// classPatternTypes maps the names usable in a class pattern such as
+// `case int(n):` to the object type they match.
+var classPatternTypes = map[string]object.ObjectType{
+	"int":    object.INTEGER_OBJ,
+	"float":  object.FLOAT_OBJ,
+	"string": object.STRING_OBJ,
+	"list":   object.ARRAY_OBJ,
+	"tuple":  object.TUPLE_OBJ,
+}
+
+func evalMatchStatement(ms *ast.MatchStatement, env *object.Environment) object.Object {
+	subject := Eval(ms.Subject, env)
+	if isError(subject) {
+		return subject
+	}
+
+	for _, clause := range ms.Cases {
+		bindings := make(map[string]object.Object)
+		matched, err := matchPattern(clause.Pattern, subject, bindings, env)
+		if err != nil {
+			return err
+		}
+		if !matched {
+			continue
+		}
+
+		// Captures are only visible to the guard until the arm is chosen
+		if clause.Guard != nil {
+			guardEnv := object.NewEnclosedEnvironment(env)
+			for name, val := range bindings {
+				guardEnv.Set(name, val)
+			}
+			guard := Eval(clause.Guard, guardEnv)
+			if isError(guard) {
+				return guard
+			}
+			truthy, err := isTruthy(guard)
+			if err != nil {
+				return err
+			}
+			if !truthy {
+				continue
+			}
+		}
+
+		for name, val := range bindings {
+			if err := assign(env, name, val); err != nil {
+				return err
+			}
+		}
+		return Eval(clause.Body, env)
+	}
+
+	return newError("no case matched value: %s", subject.Inspect())
+}
+
+func matchPattern(
+	pattern ast.Pattern,
+	subject object.Object,
+	bindings map[string]object.Object,
+	env *object.Environment,
+) (bool, *object.Error) {
+	switch pattern := pattern.(type) {
+	case *ast.WildcardPattern:
+		return true, nil
+	case *ast.CapturePattern:
+		bindings[pattern.Name.Value] = subject
+		return true, nil
+	case *ast.LiteralPattern:
+		value := Eval(pattern.Value, env)
+		if err, ok := value.(*object.Error); ok {
+			return false, err
+		}
+		return valuesEqual(value, subject), nil
+	case *ast.SequencePattern:
+		return matchSequencePattern(pattern, subject, bindings, env)
+	case *ast.HashPattern:
+		return matchHashPattern(pattern, subject, bindings, env)
+	case *ast.ClassPattern:
+		return matchClassPattern(pattern, subject, bindings, env)
+	default:
+		return false, newError("unsupported case pattern: %s", pattern.String())
+	}
+}
+
+func matchSequencePattern(
+	pattern *ast.SequencePattern,
+	subject object.Object,
+	bindings map[string]object.Object,
+	env *object.Environment,
+) (bool, *object.Error) {
+	var elements []object.Object
+	switch subject := subject.(type) {
+	case *object.Array:
+		elements = subject.Elements
+	case *object.Tuple:
+		elements = subject.Elements
+	default:
+		return false, nil
+	}
+
+	star := -1
+	for i, elem := range pattern.Elements {
+		if _, ok := elem.(*ast.StarPattern); ok {
+			star = i
+		}
+	}
+
+	if star < 0 {
+		if len(elements) != len(pattern.Elements) {
+			return false, nil
+		}
+		for i, elem := range pattern.Elements {
+			matched, err := matchPattern(elem, elements[i], bindings, env)
+			if err != nil || !matched {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+
+	before := pattern.Elements[:star]
+	after := pattern.Elements[star+1:]
+	if len(elements) < len(before)+len(after) {
+		return false, nil
+	}
+	for i, elem := range before {
+		matched, err := matchPattern(elem, elements[i], bindings, env)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+	offset := len(elements) - len(after)
+	for i, elem := range after {
+		matched, err := matchPattern(elem, elements[offset+i], bindings, env)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+
+	if name := pattern.Elements[star].(*ast.StarPattern).Name; name != nil {
+		rest := make([]object.Object, offset-len(before))
+		copy(rest, elements[len(before):offset])
+		bindings[name.Value] = &object.Array{Elements: rest}
+	}
+	return true, nil
+}
+
+func matchHashPattern(
+	pattern *ast.HashPattern,
+	subject object.Object,
+	bindings map[string]object.Object,
+	env *object.Environment,
+) (bool, *object.Error) {
+	hash, ok := subject.(*object.Hash)
+	if !ok {
+		return false, nil
+	}
+
+	for i, keyNode := range pattern.Keys {
+		key := Eval(keyNode, env)
+		if err, ok := key.(*object.Error); ok {
+			return false, err
+		}
+		hashKey, ok := object.HashKeyOf(key)
+		if !ok {
+			return false, newError("unusable as hash key: %s", key.Type())
+		}
+		pair, ok := hash.Pairs[hashKey]
+		if !ok {
+			return false, nil
+		}
+		matched, err := matchPattern(pattern.Values[i], pair.Value, bindings, env)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func matchClassPattern(
+	pattern *ast.ClassPattern,
+	subject object.Object,
+	bindings map[string]object.Object,
+	env *object.Environment,
+) (bool, *object.Error) {
+	objType, ok := classPatternTypes[pattern.Type.Value]
+	if !ok {
+		return false, newError("%s is not a type usable in a case pattern", pattern.Type.Value)
+	}
+	if len(pattern.Arguments) > 1 {
+		return false, newError("%s() accepts at most one sub-pattern, got %d",
+			pattern.Type.Value, len(pattern.Arguments))
+	}
+	if subject.Type() != objType {
+		return false, nil
+	}
+	if len(pattern.Arguments) == 0 {
+		return true, nil
+	}
+	// A single sub-pattern is matched against the subject itself => int(n)
+	return matchPattern(pattern.Arguments[0], subject, bindings, env)
+}
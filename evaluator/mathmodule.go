@@ -0,0 +1,391 @@
+package evaluator
+
+import (
+	"math"
+
+	"thecarrionlanguage/object"
+)
+
+// mathModule is the native `math` module. Its functions accept integers and
+// floats alike and report arguments outside their domain, and results too
+// large for a float, as errors instead of returning NaN or infinity.
+func mathModule() map[string]object.Object {
+	finite := func(x float64) bool { return !math.IsInf(x, 0) }
+	return map[string]object.Object{
+		"pi":  &object.Float{Value: math.Pi},
+		"e":   &object.Float{Value: math.E},
+		"inf": &object.Float{Value: math.Inf(1)},
+		"nan": &object.Float{Value: math.NaN()},
+
+		"sqrt":  mathFunction("sqrt", math.Sqrt, func(x float64) bool { return x >= 0 }),
+		"exp":   mathFunction("exp", math.Exp, nil),
+		"sin":   mathFunction("sin", math.Sin, finite),
+		"cos":   mathFunction("cos", math.Cos, finite),
+		"tan":   mathFunction("tan", math.Tan, finite),
+		"asin":  mathFunction("asin", math.Asin, func(x float64) bool { return x >= -1 && x <= 1 }),
+		"acos":  mathFunction("acos", math.Acos, func(x float64) bool { return x >= -1 && x <= 1 }),
+		"atan":  mathFunction("atan", math.Atan, nil),
+		"sinh":  mathFunction("sinh", math.Sinh, nil),
+		"cosh":  mathFunction("cosh", math.Cosh, nil),
+		"tanh":  mathFunction("tanh", math.Tanh, nil),
+		"asinh": mathFunction("asinh", math.Asinh, nil),
+		"acosh": mathFunction("acosh", math.Acosh, func(x float64) bool { return x >= 1 }),
+		"atanh": mathFunction("atanh", math.Atanh, func(x float64) bool { return x > -1 && x < 1 }),
+
+		"atan2":     &object.Builtin{Fn: mathAtan2},
+		"pow":       &object.Builtin{Fn: mathPow},
+		"log":       &object.Builtin{Fn: mathLog},
+		"floor":     &object.Builtin{Fn: mathRounding("floor", math.Floor)},
+		"ceil":      &object.Builtin{Fn: mathRounding("ceil", math.Ceil)},
+		"round":     &object.Builtin{Fn: mathRound},
+		"abs":       &object.Builtin{Fn: mathAbs},
+		"gcd":       &object.Builtin{Fn: mathGcdLcm("gcd", 0, gcd)},
+		"lcm":       &object.Builtin{Fn: mathGcdLcm("lcm", 1, lcm)},
+		"isclose":   &object.Builtin{KwFn: mathIsClose},
+		"factorial": &object.Builtin{Fn: mathFactorial},
+		"isqrt":     &object.Builtin{Fn: mathIsqrt},
+	}
+}
+
+func numberArgument(name string, obj object.Object) (float64, *object.Error) {
+	if !isNumeric(obj) {
+		return 0, newError("%s() argument must be INTEGER or FLOAT, got %s", name, obj.Type())
+	}
+	return toFloat(obj), nil
+}
+
+func integerArgument(name string, obj object.Object) (int64, *object.Error) {
+	n, ok := obj.(*object.Integer)
+	if !ok {
+		return 0, newError("%s() argument must be INTEGER, got %s", name, obj.Type())
+	}
+	return n.Value, nil
+}
+
+// floatResult wraps the result of a float function of args, turning a
+// result that overflowed from finite arguments into an error.
+func floatResult(result float64, args ...float64) object.Object {
+	if math.IsInf(result, 0) {
+		for _, arg := range args {
+			if math.IsInf(arg, 0) {
+				return &object.Float{Value: result}
+			}
+		}
+		return newError("math range error")
+	}
+	return &object.Float{Value: result}
+}
+
+// mathFunction wraps a float function of one argument. domain, if given,
+// reports which arguments are valid; NaN is always passed through.
+func mathFunction(name string, fn func(float64) float64, domain func(float64) bool) *object.Builtin {
+	return &object.Builtin{Fn: func(args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError("wrong number of arguments. got=%d, want=1", len(args))
+		}
+		x, err := numberArgument(name, args[0])
+		if err != nil {
+			return err
+		}
+		if domain != nil && !math.IsNaN(x) && !domain(x) {
+			return newError("math domain error")
+		}
+		return floatResult(fn(x), x)
+	}}
+}
+
+func mathAtan2(args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+	y, err := numberArgument("atan2", args[0])
+	if err != nil {
+		return err
+	}
+	x, err := numberArgument("atan2", args[1])
+	if err != nil {
+		return err
+	}
+	return &object.Float{Value: math.Atan2(y, x)}
+}
+
+func mathPow(args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+	x, err := numberArgument("pow", args[0])
+	if err != nil {
+		return err
+	}
+	y, err := numberArgument("pow", args[1])
+	if err != nil {
+		return err
+	}
+	if x == 0 && y < 0 || x < 0 && !math.IsInf(x, 0) && y != math.Trunc(y) {
+		return newError("math domain error")
+	}
+	return floatResult(math.Pow(x, y), x, y)
+}
+
+// mathLog returns the natural logarithm, or the logarithm in the optional
+// base.
+func mathLog(args ...object.Object) object.Object {
+	if len(args) != 1 && len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+	x, err := numberArgument("log", args[0])
+	if err != nil {
+		return err
+	}
+	if x <= 0 {
+		return newError("math domain error")
+	}
+	if len(args) == 1 {
+		return &object.Float{Value: math.Log(x)}
+	}
+	base, err := numberArgument("log", args[1])
+	if err != nil {
+		return err
+	}
+	if base <= 0 || base == 1 {
+		return newError("math domain error")
+	}
+	return &object.Float{Value: math.Log(x) / math.Log(base)}
+}
+
+// floatToInteger converts a whole float to an integer, failing for values
+// that have no integer equivalent.
+func floatToInteger(x float64) object.Object {
+	if math.IsNaN(x) || math.IsInf(x, 0) || x < math.MinInt64 || x >= math.MaxInt64 {
+		return newError("cannot convert %v to INTEGER", x)
+	}
+	return &object.Integer{Value: int64(x)}
+}
+
+// mathRounding implements floor and ceil, which return integers.
+func mathRounding(name string, fn func(float64) float64) object.BuiltinFunction {
+	return func(args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError("wrong number of arguments. got=%d, want=1", len(args))
+		}
+		if n, ok := args[0].(*object.Integer); ok {
+			return n
+		}
+		x, err := numberArgument(name, args[0])
+		if err != nil {
+			return err
+		}
+		return floatToInteger(fn(x))
+	}
+}
+
+// mathRound rounds half to even. Without digits it returns an integer;
+// with digits it rounds to that many decimal places, which may be negative,
+// keeping the type of the argument.
+func mathRound(args ...object.Object) object.Object {
+	if len(args) != 1 && len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+	x, err := numberArgument("round", args[0])
+	if err != nil {
+		return err
+	}
+	if len(args) == 1 {
+		if n, ok := args[0].(*object.Integer); ok {
+			return n
+		}
+		return floatToInteger(math.RoundToEven(x))
+	}
+	digits, err := integerArgument("round", args[1])
+	if err != nil {
+		return err
+	}
+	if n, ok := args[0].(*object.Integer); ok && digits >= 0 {
+		return n
+	}
+	scale := math.Pow(10, float64(digits))
+	var rounded float64
+	switch {
+	case scale == 0:
+		rounded = 0
+	case math.IsInf(x*scale, 0):
+		rounded = x
+	default:
+		rounded = math.RoundToEven(x*scale) / scale
+	}
+	if _, ok := args[0].(*object.Integer); ok {
+		return floatToInteger(rounded)
+	}
+	return &object.Float{Value: rounded}
+}
+
+func mathAbs(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+	switch arg := args[0].(type) {
+	case *object.Integer:
+		n, ok := absInt(arg.Value)
+		if !ok {
+			return newError("abs() result does not fit in INTEGER")
+		}
+		return &object.Integer{Value: n}
+	case *object.Float:
+		return &object.Float{Value: math.Abs(arg.Value)}
+	}
+	return newError("abs() argument must be INTEGER or FLOAT, got %s", args[0].Type())
+}
+
+// absInt returns |n|, or false when it does not fit in an int64.
+func absInt(n int64) (int64, bool) {
+	if n == math.MinInt64 {
+		return 0, false
+	}
+	if n < 0 {
+		return -n, true
+	}
+	return n, true
+}
+
+func gcd(a, b int64) (int64, bool) {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return absInt(a)
+}
+
+func lcm(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	a, okA := absInt(a)
+	b, okB := absInt(b)
+	if !okA || !okB {
+		return 0, false
+	}
+	g, _ := gcd(a, b)
+	if a/g > math.MaxInt64/b {
+		return 0, false
+	}
+	return a / g * b, true
+}
+
+// mathGcdLcm folds any number of integers with combine, which reports false
+// when its result does not fit in an int64. The fold starts from the first
+// argument rather than from identity, so that -2**63 can still be reduced by
+// the other arguments; the final combine with identity takes the absolute
+// value.
+func mathGcdLcm(name string, identity int64, combine func(a, b int64) (int64, bool)) object.BuiltinFunction {
+	return func(args ...object.Object) object.Object {
+		result := identity
+		for i, arg := range args {
+			n, err := integerArgument(name, arg)
+			if err != nil {
+				return err
+			}
+			if i == 0 {
+				result = n
+				continue
+			}
+			var ok bool
+			if result, ok = combine(result, n); !ok {
+				return newError("%s() result does not fit in INTEGER", name)
+			}
+		}
+		result, ok := combine(result, identity)
+		if !ok {
+			return newError("%s() result does not fit in INTEGER", name)
+		}
+		return &object.Integer{Value: result}
+	}
+}
+
+// mathIsClose reports whether two numbers are equal within the relative
+// tolerance rel_tol (default 1e-9) or the absolute tolerance abs_tol
+// (default 0), which can be passed positionally or by keyword.
+func mathIsClose(kwargs map[string]object.Object, args ...object.Object) object.Object {
+	if len(args) < 2 || len(args) > 4 {
+		return newError("wrong number of arguments. got=%d, want=2 to 4", len(args))
+	}
+	values := []float64{0, 0, 1e-9, 0}
+	names := []string{"a", "b", "rel_tol", "abs_tol"}
+	for i, arg := range args {
+		value, err := numberArgument("isclose", arg)
+		if err != nil {
+			return err
+		}
+		values[i] = value
+	}
+	for name, arg := range kwargs {
+		i := 2
+		for i < len(names) && names[i] != name {
+			i++
+		}
+		if i == len(names) {
+			return newError("isclose() got an unexpected keyword argument %s", name)
+		}
+		if i < len(args) {
+			return newError("multiple values for argument %s", name)
+		}
+		value, err := numberArgument("isclose", arg)
+		if err != nil {
+			return err
+		}
+		values[i] = value
+	}
+	a, b, relTol, absTol := values[0], values[1], values[2], values[3]
+	if relTol < 0 || absTol < 0 {
+		return newError("tolerances must be non-negative")
+	}
+	if a == b {
+		return TRUE
+	}
+	if math.IsInf(a, 0) || math.IsInf(b, 0) {
+		return FALSE
+	}
+	diff := math.Abs(a - b)
+	return nativeBoolToBooleanObject(diff <= relTol*math.Abs(a) || diff <= relTol*math.Abs(b) || diff <= absTol)
+}
+
+func mathFactorial(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+	n, err := integerArgument("factorial", args[0])
+	if err != nil {
+		return err
+	}
+	if n < 0 {
+		return newError("factorial() not defined for negative values")
+	}
+	result := int64(1)
+	for i := int64(2); i <= n; i++ {
+		if result > math.MaxInt64/i {
+			return newError("factorial() result does not fit in INTEGER")
+		}
+		result *= i
+	}
+	return &object.Integer{Value: result}
+}
+
+// mathIsqrt returns the largest integer whose square is at most n.
+func mathIsqrt(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+	n, err := integerArgument("isqrt", args[0])
+	if err != nil {
+		return err
+	}
+	if n < 0 {
+		return newError("isqrt() argument must be non-negative")
+	}
+	root := int64(math.Sqrt(float64(n)))
+	for root*root > n {
+		root--
+	}
+	for (root+1)*(root+1) <= n && (root+1)*(root+1) > 0 {
+		root++
+	}
+	return &object.Integer{Value: root}
+}
@@ -0,0 +1,318 @@
+package evaluator
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"thecarrionlanguage/ast"
+	"thecarrionlanguage/object"
+)
+
+// method is a spell attached to a builtin type. Looking it up on a value
+// binds the value as the receiver, so `items.append(x)` calls fn(items, x).
+type method struct {
+	minArgs, maxArgs int // not counting the receiver
+	fn               func(receiver object.Object, args ...object.Object) object.Object
+}
+
+// receiverFirst turns a builtin that takes the receiver as its first
+// argument into a method.
+func receiverFirst(minArgs, maxArgs int, builtin object.BuiltinFunction) method {
+	return method{minArgs, maxArgs, func(receiver object.Object, args ...object.Object) object.Object {
+		return builtin(append([]object.Object{receiver}, args...)...)
+	}}
+}
+
+// methods holds the method table of each builtin type.
+var methods = map[object.ObjectType]map[string]method{
+	object.STRING_OBJ: {
+		"split":      {0, 1, stringSplit},
+		"join":       {1, 1, stringJoin},
+		"strip":      {0, 1, stringStrip},
+		"replace":    {2, 2, stringReplace},
+		"startswith": {1, 1, stringAffix("startswith", strings.HasPrefix)},
+		"endswith":   {1, 1, stringAffix("endswith", strings.HasSuffix)},
+		"upper":      {0, 0, stringCase(strings.ToUpper)},
+		"lower":      {0, 0, stringCase(strings.ToLower)},
+		"find":       {1, 1, stringFind},
+		"index":      receiverFirst(1, 1, indexBuiltin),
+		"count":      receiverFirst(1, 1, countBuiltin),
+	},
+	object.ARRAY_OBJ: {
+		"append":  receiverFirst(1, 1, appendBuiltin("append")),
+		"extend":  {1, 1, arrayExtend},
+		"pop":     receiverFirst(0, 1, popBuiltin),
+		"insert":  receiverFirst(2, 2, insertBuiltin),
+		"remove":  receiverFirst(1, 1, removeBuiltin),
+		"reverse": receiverFirst(0, 0, reverseBuiltin),
+		"index":   receiverFirst(1, 1, indexBuiltin),
+		"count":   receiverFirst(1, 1, countBuiltin),
+	},
+	object.TUPLE_OBJ: {
+		"index": receiverFirst(1, 1, indexBuiltin),
+		"count": receiverFirst(1, 1, countBuiltin),
+	},
+	object.HASH_OBJ: {
+		"keys":   receiverFirst(0, 0, hashView("keys", func(pair object.HashPair) object.Object { return pair.Key })),
+		"values": receiverFirst(0, 0, hashView("values", func(pair object.HashPair) object.Object { return pair.Value })),
+		"items": receiverFirst(0, 0, hashView("items", func(pair object.HashPair) object.Object {
+			return &object.Tuple{Elements: []object.Object{pair.Key, pair.Value}}
+		})),
+		"get":    {1, 2, hashGet},
+		"update": {1, 1, hashUpdate},
+	},
+	object.SET_OBJ: {
+		"add":     {1, 1, setAdd},
+		"remove":  {1, 1, setRemove},
+		"discard": {1, 1, setDiscard},
+	},
+	object.STREAM_OBJ: {
+		"read":      {0, 0, streamRead},
+		"readline":  {0, 0, streamReadLine},
+		"readlines": {0, 0, streamReadLines},
+		"write":     {1, 1, streamWrite},
+	},
+	object.FILE_OBJ: {
+		"read":      {0, 0, streamRead},
+		"readline":  {0, 0, streamReadLine},
+		"readlines": {0, 0, streamReadLines},
+		"write":     {1, 1, streamWrite},
+		"close":     {0, 0, fileClose},
+	},
+	object.LOCK_OBJ: {
+		"acquire": {0, 0, lockAcquire},
+		"release": {0, 0, lockRelease},
+		"locked":  {0, 0, lockLocked},
+	},
+}
+
+func evalMemberExpression(node *ast.MemberExpression, env *object.Environment) object.Object {
+	obj := Eval(node.Object, env)
+	if isError(obj) {
+		return obj
+	}
+	name := node.Member.Value
+	if module, ok := obj.(*object.Module); ok {
+		if member, ok := module.Env.Get(name); ok {
+			return member
+		}
+		return newError("module %s has no attribute %s", module.Name, name)
+	}
+	if instance, ok := obj.(object.Instance); ok {
+		if spell, ok := instance.Method(name); ok {
+			return spell
+		}
+	}
+	m, ok := methods[obj.Type()][name]
+	if !ok {
+		return newError("%s has no attribute %s", obj.Type(), name)
+	}
+	return &object.Builtin{Name: name, Fn: func(args ...object.Object) object.Object {
+		if len(args) < m.minArgs || len(args) > m.maxArgs {
+			return newError("wrong number of arguments to `%s`. got=%d, want=%s",
+				name, len(args), arityString(m.minArgs, m.maxArgs))
+		}
+		return m.fn(obj, args...)
+	}}
+}
+
+func arityString(minArgs, maxArgs int) string {
+	if minArgs == maxArgs {
+		return strconv.Itoa(minArgs)
+	}
+	return strconv.Itoa(minArgs) + " or " + strconv.Itoa(maxArgs)
+}
+
+// dirBuiltin lists the methods of a value, or the members of a module, in
+// alphabetical order.
+func dirBuiltin(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+	var names []string
+	if module, ok := args[0].(*object.Module); ok {
+		names = module.Env.Names()
+	} else {
+		for name := range methods[args[0].Type()] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	elements := make([]object.Object, len(names))
+	for i, name := range names {
+		elements[i] = &object.String{Value: name}
+	}
+	return &object.Array{Elements: elements}
+}
+
+// hashView returns a builtin listing one view of each pair of a hash, in
+// insertion order.
+func hashView(name string, view func(object.HashPair) object.Object) object.BuiltinFunction {
+	return func(args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError("wrong number of arguments. got=%d, want=1", len(args))
+		}
+		hash, ok := args[0].(*object.Hash)
+		if !ok {
+			return newError("argument to `%s` must be HASH, got %s", name, args[0].Type())
+		}
+		elements := []object.Object{}
+		for _, pair := range hash.OrderedPairs() {
+			elements = append(elements, view(pair))
+		}
+		return &object.Array{Elements: elements}
+	}
+}
+
+func stringArgument(name string, obj object.Object) (*object.String, *object.Error) {
+	str, ok := obj.(*object.String)
+	if !ok {
+		return nil, newError("argument to `%s` must be STRING, got %s", name, obj.Type())
+	}
+	return str, nil
+}
+
+// stringSplit splits on the separator, or on runs of whitespace when it is
+// omitted or none.
+func stringSplit(receiver object.Object, args ...object.Object) object.Object {
+	value := receiver.(*object.String).Value
+	var parts []string
+	if len(args) == 0 || args[0] == NONE {
+		parts = strings.Fields(value)
+	} else {
+		sep, err := stringArgument("split", args[0])
+		if err != nil {
+			return err
+		}
+		if sep.Value == "" {
+			return newError("empty separator")
+		}
+		parts = strings.Split(value, sep.Value)
+	}
+	elements := make([]object.Object, len(parts))
+	for i, part := range parts {
+		elements[i] = &object.String{Value: part}
+	}
+	return &object.Array{Elements: elements}
+}
+
+// stringJoin joins the strings of an iterable with the receiver between them.
+func stringJoin(receiver object.Object, args ...object.Object) object.Object {
+	elements, errObj := collectArgument("join", args[0])
+	if errObj != nil {
+		return errObj
+	}
+	parts := make([]string, len(elements))
+	for i, el := range elements {
+		str, ok := el.(*object.String)
+		if !ok {
+			return newError("sequence item %d: expected STRING, got %s", i, el.Type())
+		}
+		parts[i] = str.Value
+	}
+	return &object.String{Value: strings.Join(parts, receiver.(*object.String).Value)}
+}
+
+// stringStrip trims whitespace, or the given characters, from both ends.
+func stringStrip(receiver object.Object, args ...object.Object) object.Object {
+	value := receiver.(*object.String).Value
+	if len(args) == 0 || args[0] == NONE {
+		return &object.String{Value: strings.TrimSpace(value)}
+	}
+	chars, err := stringArgument("strip", args[0])
+	if err != nil {
+		return err
+	}
+	return &object.String{Value: strings.Trim(value, chars.Value)}
+}
+
+func stringReplace(receiver object.Object, args ...object.Object) object.Object {
+	old, err := stringArgument("replace", args[0])
+	if err != nil {
+		return err
+	}
+	replacement, err := stringArgument("replace", args[1])
+	if err != nil {
+		return err
+	}
+	value := receiver.(*object.String).Value
+	return &object.String{Value: strings.ReplaceAll(value, old.Value, replacement.Value)}
+}
+
+func stringAffix(name string, test func(s, affix string) bool) func(object.Object, ...object.Object) object.Object {
+	return func(receiver object.Object, args ...object.Object) object.Object {
+		affix, err := stringArgument(name, args[0])
+		if err != nil {
+			return err
+		}
+		return nativeBoolToBooleanObject(test(receiver.(*object.String).Value, affix.Value))
+	}
+}
+
+func stringCase(convert func(string) string) func(object.Object, ...object.Object) object.Object {
+	return func(receiver object.Object, args ...object.Object) object.Object {
+		return &object.String{Value: convert(receiver.(*object.String).Value)}
+	}
+}
+
+// stringFind returns the position of the first occurrence of a substring,
+// or -1 when there is none.
+func stringFind(receiver object.Object, args ...object.Object) object.Object {
+	sub, err := stringArgument("find", args[0])
+	if err != nil {
+		return err
+	}
+	return &object.Integer{Value: int64(runeIndex(receiver.(*object.String).Value, sub.Value))}
+}
+
+// runeIndex is strings.Index counted in characters rather than bytes, the
+// unit that len and slicing use for strings.
+func runeIndex(s, sub string) int {
+	idx := strings.Index(s, sub)
+	if idx < 0 {
+		return -1
+	}
+	return utf8.RuneCountInString(s[:idx])
+}
+
+// arrayExtend appends every element of an iterable to the list.
+func arrayExtend(receiver object.Object, args ...object.Object) object.Object {
+	elements, errObj := collectArgument("extend", args[0])
+	if errObj != nil {
+		return errObj
+	}
+	arr := receiver.(*object.Array)
+	arr.Elements = append(arr.Elements, elements...)
+	return NONE
+}
+
+// hashGet returns the value stored under key, or the default (none unless
+// given) when the key is missing.
+func hashGet(receiver object.Object, args ...object.Object) object.Object {
+	key, ok := object.HashKeyOf(args[0])
+	if !ok {
+		return newError("unusable as hash key: %s", args[0].Type())
+	}
+	if pair, ok := receiver.(*object.Hash).Pairs[key]; ok {
+		return pair.Value
+	}
+	if len(args) == 2 {
+		return args[1]
+	}
+	return NONE
+}
+
+// hashUpdate copies the pairs of another hash into the receiver.
+func hashUpdate(receiver object.Object, args ...object.Object) object.Object {
+	other, ok := args[0].(*object.Hash)
+	if !ok {
+		return newError("argument to `update` must be HASH, got %s", args[0].Type())
+	}
+	hash := receiver.(*object.Hash)
+	for _, key := range other.Keys {
+		hash.Set(key, other.Pairs[key])
+	}
+	return NONE
+}
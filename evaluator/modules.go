@@ -0,0 +1,109 @@
+package evaluator
+
+import (
+	"strings"
+
+	"thecarrionlanguage/ast"
+	"thecarrionlanguage/lexer"
+	"thecarrionlanguage/munin"
+	"thecarrionlanguage/object"
+	"thecarrionlanguage/parser"
+)
+
+// NativeModule builds the members of a module implemented in Go.
+type NativeModule func() map[string]object.Object
+
+// nativeModules are importable alongside the Carrion modules of munin. A
+// module can be both: its Go members are defined first, and its Carrion
+// source runs after them.
+var nativeModules = map[string]NativeModule{
+	"math":      mathModule,
+	"itertools": itertoolsModule,
+}
+
+// RegisterNativeModule makes a module implemented in Go importable as name.
+func RegisterNativeModule(name string, module NativeModule) {
+	nativeModules[name] = module
+}
+
+func evalImportStatement(node *ast.ImportStatement, env *object.Environment) object.Object {
+	module, err := interpreterOf(env).importModule(node.Name.Value)
+	if err != nil {
+		return err
+	}
+	name := node.Name.Value
+	if node.Alias != nil {
+		name = node.Alias.Value
+	}
+	if err := assign(env, name, module); err != nil {
+		return err
+	}
+	return NONE
+}
+
+// importModule loads a module, or returns the one this interpreter already
+// imported under name.
+func (interp *Interpreter) importModule(name string) (*object.Module, *object.Error) {
+	if module, ok := interp.modules[name]; ok {
+		return module, nil
+	}
+	if interp.importing[name] {
+		return nil, newError("circular import of %s", name)
+	}
+
+	build, native := interp.nativeModule(name)
+	source, carrion := munin.Source(name)
+	if !native && !carrion {
+		return nil, newError("no module named %s", name)
+	}
+
+	env := interp.NewEnvironment()
+	if native {
+		for member, value := range build() {
+			if builtin, ok := value.(*object.Builtin); ok && builtin.Name == "" {
+				builtin.Name = member
+			}
+			env.Set(member, value)
+		}
+	}
+	if carrion {
+		p := parser.New(lexer.New(source))
+		program := p.ParseProgram()
+		if len(p.Errors()) > 0 {
+			return nil, newError("module %s has syntax errors: %s", name, strings.Join(p.Errors(), "; "))
+		}
+		interp.importing[name] = true
+		result := Eval(program, env)
+		delete(interp.importing, name)
+		if errObj, ok := result.(*object.Error); ok {
+			return nil, newError("error in module %s: %s", name, errObj.Message)
+		}
+	}
+
+	module := &object.Module{Name: name, Env: env}
+	interp.modules[name] = module
+	return module, nil
+}
+
+// nativeModule returns the builder of a Go module. sys is built per
+// interpreter, since it exposes the interpreter's own arguments and streams.
+func (interp *Interpreter) nativeModule(name string) (NativeModule, bool) {
+	if name == "sys" {
+		return interp.sysModule, true
+	}
+	build, ok := nativeModules[name]
+	return build, ok
+}
+
+func (interp *Interpreter) sysModule() map[string]object.Object {
+	argv := make([]object.Object, len(interp.Args))
+	for i, arg := range interp.Args {
+		argv[i] = &object.String{Value: arg}
+	}
+	return map[string]object.Object{
+		"argv":   &object.Array{Elements: argv},
+		"stdin":  interp.stdin,
+		"stdout": interp.stdout,
+		"stderr": interp.stderr,
+	}
+}
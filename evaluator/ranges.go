@@ -0,0 +1,185 @@
+package evaluator
+
+import (
+	"thecarrionlanguage/ast"
+	"thecarrionlanguage/object"
+)
+
+// rangeBuiltin implements range(stop), range(start, stop) and
+// range(start, stop, step).
+func rangeBuiltin(args ...object.Object) object.Object {
+	if len(args) < 1 || len(args) > 3 {
+		return newError("wrong number of arguments. got=%d, want=1 to 3", len(args))
+	}
+	values := make([]int64, len(args))
+	for i, arg := range args {
+		n, ok := arg.(*object.Integer)
+		if !ok {
+			return newError("range() arguments must be INTEGER, got %s", arg.Type())
+		}
+		values[i] = n.Value
+	}
+	switch len(values) {
+	case 1:
+		return &object.Range{Start: 0, Stop: values[0], Step: 1}
+	case 2:
+		return &object.Range{Start: values[0], Stop: values[1], Step: 1}
+	}
+	if values[2] == 0 {
+		return newError("range() step must not be zero")
+	}
+	return &object.Range{Start: values[0], Stop: values[1], Step: values[2]}
+}
+
+func evalRangeIndexExpression(rng *object.Range, index object.Object) object.Object {
+	length := rng.Len()
+	idx, err := normalizeIndex(index, int(length))
+	if err != nil {
+		return err
+	}
+	if idx < 0 || int64(idx) >= length {
+		return NONE
+	}
+	return &object.Integer{Value: rng.At(int64(idx))}
+}
+
+// rangeContains implements `in` for ranges without walking them. Only
+// numbers with a whole value can be elements.
+func rangeContains(rng *object.Range, needle object.Object) bool {
+	switch needle := needle.(type) {
+	case *object.Integer:
+		return rng.Contains(needle.Value)
+	case *object.Float:
+		return needle.Value == float64(int64(needle.Value)) && rng.Contains(int64(needle.Value))
+	}
+	return false
+}
+
+func rangesEqual(left, right *object.Range) bool {
+	length := left.Len()
+	if length != right.Len() {
+		return false
+	}
+	switch length {
+	case 0:
+		return true
+	case 1:
+		return left.Start == right.Start
+	}
+	return left.Start == right.Start && left.Step == right.Step
+}
+
+func evalSliceExpression(node *ast.SliceExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isError(left) {
+		return left
+	}
+	bounds := make([]object.Object, 3)
+	for i, exp := range []ast.Expression{node.Start, node.Stop, node.Step} {
+		bounds[i] = NONE
+		if exp != nil {
+			bounds[i] = Eval(exp, env)
+			if isError(bounds[i]) {
+				return bounds[i]
+			}
+		}
+	}
+
+	var length int
+	switch left := left.(type) {
+	case *object.Array:
+		length = len(left.Elements)
+	case *object.Tuple:
+		length = len(left.Elements)
+	case *object.String:
+		length = len([]rune(left.Value))
+	case *object.Range:
+		length = int(left.Len())
+	default:
+		return newError("slice operator not supported: %s", left.Type())
+	}
+	start, stop, step, err := sliceIndices(bounds[0], bounds[1], bounds[2], length)
+	if err != nil {
+		return err
+	}
+
+	switch left := left.(type) {
+	case *object.Array:
+		return &object.Array{Elements: sliceElements(left.Elements, start, stop, step)}
+	case *object.Tuple:
+		return &object.Tuple{Elements: sliceElements(left.Elements, start, stop, step)}
+	case *object.String:
+		runes := []rune(left.Value)
+		sliced := []rune{}
+		for i := start; step > 0 && i < stop || step < 0 && i > stop; i += step {
+			sliced = append(sliced, runes[i])
+		}
+		return &object.String{Value: string(sliced)}
+	default:
+		rng := left.(*object.Range)
+		return &object.Range{
+			Start: rng.At(int64(start)),
+			Stop:  rng.At(int64(stop)),
+			Step:  rng.Step * int64(step),
+		}
+	}
+}
+
+// sliceIndices resolves the bounds of a slice against a sequence of the
+// given length the way Python does: omitted bounds default to the ends in
+// the direction of step, negative bounds count from the end, and bounds
+// past either end are clamped.
+func sliceIndices(startObj, stopObj, stepObj object.Object, length int) (start, stop, step int, err *object.Error) {
+	bound := func(obj object.Object, def int) (int, *object.Error) {
+		if obj == NONE {
+			return def, nil
+		}
+		n, ok := obj.(*object.Integer)
+		if !ok {
+			return 0, newError("slice indices must be INTEGER or none, got %s", obj.Type())
+		}
+		return int(n.Value), nil
+	}
+	if step, err = bound(stepObj, 1); err != nil {
+		return
+	}
+	if step == 0 {
+		return 0, 0, 0, newError("slice step cannot be zero")
+	}
+
+	lower, upper := 0, length
+	if step < 0 {
+		lower, upper = -1, length-1
+	}
+	clamp := func(i int) int {
+		if i < 0 {
+			i += length
+		}
+		return max(lower, min(i, upper))
+	}
+	defStart, defStop := lower, upper
+	if step < 0 {
+		defStart, defStop = upper, lower
+	}
+	if start, err = bound(startObj, defStart); err != nil {
+		return
+	}
+	if stop, err = bound(stopObj, defStop); err != nil {
+		return
+	}
+	if startObj != NONE {
+		start = clamp(start)
+	}
+	if stopObj != NONE {
+		stop = clamp(stop)
+	}
+	return start, stop, step, nil
+}
+
+func sliceElements(elements []object.Object, start, stop, step int) []object.Object {
+	sliced := []object.Object{}
+	for i := start; step > 0 && i < stop || step < 0 && i > stop; i += step {
+		sliced = append(sliced, elements[i])
+	}
+	return sliced
+}
@@ -0,0 +1,69 @@
+package evaluator
+
+import (
+	"thecarrionlanguage/ast"
+	"thecarrionlanguage/object"
+	"thecarrionlanguage/token"
+)
+
+// assign rebinds name the way an assignment statement does, following
+// global and nonlocal declarations and refusing to overwrite a constant.
+func assign(env *object.Environment, name string, val object.Object) *object.Error {
+	if env.SetsConstant(name) {
+		return newError("cannot assign to constant %s", name)
+	}
+	env.Set(name, val)
+	return nil
+}
+
+func evalVarStatement(node *ast.VarStatement, env *object.Environment) object.Object {
+	name := node.Name.Value
+	if env.HasLocal(name) && env.IsConst(name) {
+		return newError("cannot assign to constant %s", name)
+	}
+
+	var val object.Object = NONE
+	if node.Value != nil {
+		val = Eval(node.Value, env)
+		if isError(val) {
+			return val
+		}
+	}
+	env.Declare(name, val, node.Token.Type == token.CONST)
+	return nil
+}
+
+// evalScopeStatement points the listed names at the global scope, or for
+// nonlocal at the nearest enclosing spell scope that already binds them.
+func evalScopeStatement(node *ast.ScopeStatement, env *object.Environment) object.Object {
+	global := env.Global()
+	for _, ident := range node.Names {
+		name := ident.Value
+		if env.HasLocal(name) {
+			return newError("%s is assigned before %s declaration", name, node.Token.Literal)
+		}
+
+		if node.Token.Type == token.GLOBAL {
+			if env != global {
+				env.Refer(name, global)
+			}
+			continue
+		}
+
+		if env == global {
+			return newError("nonlocal declaration not allowed at top level")
+		}
+		var target *object.Environment
+		for scope := env.Outer(); scope != nil && scope != global; scope = scope.Outer() {
+			if scope.Owner(name) == scope {
+				target = scope
+				break
+			}
+		}
+		if target == nil {
+			return newError("no binding for nonlocal %s found", name)
+		}
+		env.Refer(name, target)
+	}
+	return nil
+}
@@ -0,0 +1,447 @@
+package evaluator
+
+import (
+	"sort"
+	"strings"
+
+	"thecarrionlanguage/object"
+)
+
+// The list builtins below change their argument in place, like the list
+// methods they mirror in Python, and return none unless noted otherwise.
+
+func arrayArgument(name string, obj object.Object) (*object.Array, *object.Error) {
+	arr, ok := obj.(*object.Array)
+	if !ok {
+		return nil, newError("argument to `%s` must be ARRAY, got %s", name, obj.Type())
+	}
+	return arr, nil
+}
+
+// normalizeIndex resolves a possibly negative index against length.
+func normalizeIndex(index object.Object, length int) (int, *object.Error) {
+	i, ok := index.(*object.Integer)
+	if !ok {
+		return 0, newError("index must be INTEGER, got %s", index.Type())
+	}
+	idx := int(i.Value)
+	if idx < 0 {
+		idx += length
+	}
+	return idx, nil
+}
+
+func appendBuiltin(name string) func(args ...object.Object) object.Object {
+	return func(args ...object.Object) object.Object {
+		if len(args) != 2 {
+			return newError("wrong number of arguments. got=%d, want=2", len(args))
+		}
+		arr, err := arrayArgument(name, args[0])
+		if err != nil {
+			return err
+		}
+		arr.Elements = append(arr.Elements, args[1])
+		return NONE
+	}
+}
+
+// popBuiltin removes and returns the element at the optional index, which
+// defaults to the last one.
+func popBuiltin(args ...object.Object) object.Object {
+	if len(args) != 1 && len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+	arr, err := arrayArgument("pop", args[0])
+	if err != nil {
+		return err
+	}
+	if len(arr.Elements) == 0 {
+		return newError("pop from empty list")
+	}
+	idx := len(arr.Elements) - 1
+	if len(args) == 2 {
+		idx, err = normalizeIndex(args[1], len(arr.Elements))
+		if err != nil {
+			return err
+		}
+		if idx < 0 || idx >= len(arr.Elements) {
+			return newError("pop index out of range")
+		}
+	}
+	popped := arr.Elements[idx]
+	arr.Elements = append(arr.Elements[:idx], arr.Elements[idx+1:]...)
+	return popped
+}
+
+// insertBuiltin inserts before the index, clamping it to the list bounds.
+func insertBuiltin(args ...object.Object) object.Object {
+	if len(args) != 3 {
+		return newError("wrong number of arguments. got=%d, want=3", len(args))
+	}
+	arr, err := arrayArgument("insert", args[0])
+	if err != nil {
+		return err
+	}
+	idx, err := normalizeIndex(args[1], len(arr.Elements))
+	if err != nil {
+		return err
+	}
+	idx = max(0, min(idx, len(arr.Elements)))
+	arr.Elements = append(arr.Elements, nil)
+	copy(arr.Elements[idx+1:], arr.Elements[idx:])
+	arr.Elements[idx] = args[2]
+	return NONE
+}
+
+// removeBuiltin removes the first element equal to the value.
+func removeBuiltin(args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+	arr, err := arrayArgument("remove", args[0])
+	if err != nil {
+		return err
+	}
+	for i, el := range arr.Elements {
+		if valuesEqual(el, args[1]) {
+			arr.Elements = append(arr.Elements[:i], arr.Elements[i+1:]...)
+			return NONE
+		}
+	}
+	return newError("%s is not in list", args[1].Inspect())
+}
+
+func reverseBuiltin(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+	arr, err := arrayArgument("reverse", args[0])
+	if err != nil {
+		return err
+	}
+	for i, j := 0, len(arr.Elements)-1; i < j; i, j = i+1, j-1 {
+		arr.Elements[i], arr.Elements[j] = arr.Elements[j], arr.Elements[i]
+	}
+	return NONE
+}
+
+// indexBuiltin returns the position of the first element equal to the
+// value, or of the first occurrence of a substring.
+func indexBuiltin(args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+	switch seq := args[0].(type) {
+	case *object.String:
+		sub, ok := args[1].(*object.String)
+		if !ok {
+			return newError("argument to `index` on a STRING must be STRING, got %s", args[1].Type())
+		}
+		if idx := runeIndex(seq.Value, sub.Value); idx >= 0 {
+			return &object.Integer{Value: int64(idx)}
+		}
+		return newError("substring %q not found", sub.Value)
+	case *object.Array, *object.Tuple:
+		for i, el := range sequenceElements(seq) {
+			if valuesEqual(el, args[1]) {
+				return &object.Integer{Value: int64(i)}
+			}
+		}
+		return newError("%s is not in %s", args[1].Inspect(), seq.Type())
+	default:
+		return newError("argument to `index` not supported, got %s", args[0].Type())
+	}
+}
+
+func countBuiltin(args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+	switch seq := args[0].(type) {
+	case *object.String:
+		sub, ok := args[1].(*object.String)
+		if !ok {
+			return newError("argument to `count` on a STRING must be STRING, got %s", args[1].Type())
+		}
+		return &object.Integer{Value: int64(strings.Count(seq.Value, sub.Value))}
+	case *object.Array, *object.Tuple:
+		count := 0
+		for _, el := range sequenceElements(seq) {
+			if valuesEqual(el, args[1]) {
+				count++
+			}
+		}
+		return &object.Integer{Value: int64(count)}
+	default:
+		return newError("argument to `count` not supported, got %s", args[0].Type())
+	}
+}
+
+func sequenceElements(obj object.Object) []object.Object {
+	switch obj := obj.(type) {
+	case *object.Array:
+		return obj.Elements
+	case *object.Tuple:
+		return obj.Elements
+	}
+	return nil
+}
+
+// sortedBuiltin returns a new sorted list. The optional key spell (or none)
+// and reverse flag come second and third, or as the keywords key and
+// reverse. The sort is stable in both directions.
+func sortedBuiltin(kwargs map[string]object.Object, args ...object.Object) object.Object {
+	if len(args) < 1 || len(args) > 3 {
+		return newError("wrong number of arguments. got=%d, want=1 to 3", len(args))
+	}
+	// key and reverse may be passed positionally or by keyword
+	options := []object.Object{NONE, FALSE}
+	copy(options, args[1:])
+	for name, value := range kwargs {
+		var i int
+		switch name {
+		case "key":
+			i = 0
+		case "reverse":
+			i = 1
+		default:
+			return newError("sorted() got an unexpected keyword argument %s", name)
+		}
+		if i+1 < len(args) {
+			return newError("multiple values for argument %s", name)
+		}
+		options[i] = value
+	}
+	key, reverse := options[0], options[1]
+
+	elements, errObj := collectArgument("sorted", args[0])
+	if errObj != nil {
+		return errObj
+	}
+
+	keys := elements
+	if key != NONE {
+		keys = make([]object.Object, len(elements))
+		for i, el := range elements {
+			keys[i] = callSpell(key, []object.Object{el})
+			if isError(keys[i]) {
+				return keys[i]
+			}
+		}
+	}
+	descending, err := isTruthy(reverse)
+	if err != nil {
+		return err
+	}
+
+	order := make([]int, len(elements))
+	for i := range order {
+		order[i] = i
+	}
+	var sortErr *object.Error
+	sort.SliceStable(order, func(a, b int) bool {
+		if sortErr != nil {
+			return false
+		}
+		result, err := compareValues("<", keys[order[a]], keys[order[b]], nil)
+		if err != nil {
+			sortErr = err
+			return false
+		}
+		if descending {
+			return result > 0
+		}
+		return result < 0
+	})
+	if sortErr != nil {
+		return sortErr
+	}
+
+	sorted := make([]object.Object, len(elements))
+	for i, idx := range order {
+		sorted[i] = elements[idx]
+	}
+	return &object.Array{Elements: sorted}
+}
+
+// extremeBuiltin implements min and max, which take either one iterable or
+// several values.
+func extremeBuiltin(name string, want int) func(args ...object.Object) object.Object {
+	return func(args ...object.Object) object.Object {
+		if len(args) == 0 {
+			return newError("wrong number of arguments. got=0, want at least 1")
+		}
+		elements := args
+		if len(args) == 1 {
+			var errObj object.Object
+			elements, errObj = collectArgument(name, args[0])
+			if errObj != nil {
+				return errObj
+			}
+		}
+		if len(elements) == 0 {
+			return newError("%s() arg is an empty sequence", name)
+		}
+		best := elements[0]
+		for _, el := range elements[1:] {
+			result, err := compareValues("<", el, best, nil)
+			if err != nil {
+				return err
+			}
+			if result == want {
+				best = el
+			}
+		}
+		return best
+	}
+}
+
+func sumBuiltin(args ...object.Object) object.Object {
+	if len(args) != 1 && len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+	elements, errObj := collectArgument("sum", args[0])
+	if errObj != nil {
+		return errObj
+	}
+	var total object.Object = &object.Integer{Value: 0}
+	if len(args) == 2 {
+		total = args[1]
+	}
+	for _, el := range elements {
+		total = evalInfixExpression("+", total, el)
+		if isError(total) {
+			return total
+		}
+	}
+	return total
+}
+
+// truthBuiltin implements any and all, stopping at the first element that
+// decides the answer.
+func truthBuiltin(name string, want bool) func(args ...object.Object) object.Object {
+	return func(args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError("wrong number of arguments. got=%d, want=1", len(args))
+		}
+		if !isIterable(args[0]) {
+			return newError("argument to `%s` must be iterable, got %s", name, args[0].Type())
+		}
+		iterator, errObj := iterate(args[0])
+		if errObj != nil {
+			return errObj
+		}
+		for {
+			item, ok := iterator.Next()
+			if !ok {
+				return nativeBoolToBooleanObject(!want)
+			}
+			if isError(item) {
+				return item
+			}
+			truthy, err := isTruthy(item)
+			if err != nil {
+				stopIterator(iterator)
+				return err
+			}
+			if truthy == want {
+				stopIterator(iterator)
+				return nativeBoolToBooleanObject(want)
+			}
+		}
+	}
+}
+
+// enumerateBuiltin lazily pairs each element with its position, counting
+// from the optional start.
+func enumerateBuiltin(args ...object.Object) object.Object {
+	if len(args) != 1 && len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+	if !isIterable(args[0]) {
+		return newError("argument to `enumerate` must be iterable, got %s", args[0].Type())
+	}
+	var start int64
+	if len(args) == 2 {
+		s, ok := args[1].(*object.Integer)
+		if !ok {
+			return newError("start must be INTEGER, got %s", args[1].Type())
+		}
+		start = s.Value
+	}
+	iterator, errObj := iterate(args[0])
+	if errObj != nil {
+		return errObj
+	}
+	return object.NewGenerator(func(yield func(object.Object) bool) {
+		for i := start; ; i++ {
+			item, ok := iterator.Next()
+			if !ok {
+				return
+			}
+			if isError(item) {
+				yield(item)
+				return
+			}
+			pair := &object.Tuple{Elements: []object.Object{&object.Integer{Value: i}, item}}
+			if !yield(pair) {
+				stopIterator(iterator)
+				return
+			}
+		}
+	})
+}
+
+// zipBuiltin lazily combines the iterables into tuples, stopping with the
+// shortest.
+func zipBuiltin(args ...object.Object) object.Object {
+	iterators := make([]object.Iterator, len(args))
+	for i, arg := range args {
+		if !isIterable(arg) {
+			return newError("argument %d to `zip` must be iterable, got %s", i+1, arg.Type())
+		}
+		iterator, errObj := iterate(arg)
+		if errObj != nil {
+			return errObj
+		}
+		iterators[i] = iterator
+	}
+	stopAll := func() {
+		for _, iterator := range iterators {
+			stopIterator(iterator)
+		}
+	}
+	return object.NewGenerator(func(yield func(object.Object) bool) {
+		if len(iterators) == 0 {
+			return
+		}
+		for {
+			elements := make([]object.Object, len(iterators))
+			for i, iterator := range iterators {
+				item, ok := iterator.Next()
+				if !ok {
+					stopAll()
+					return
+				}
+				if isError(item) {
+					stopAll()
+					yield(item)
+					return
+				}
+				elements[i] = item
+			}
+			if !yield(&object.Tuple{Elements: elements}) {
+				stopAll()
+				return
+			}
+		}
+	})
+}
+
+// collectArgument drains an iterable argument of the named builtin.
+func collectArgument(name string, obj object.Object) ([]object.Object, object.Object) {
+	if !isIterable(obj) {
+		return nil, newError("argument to `%s` must be iterable, got %s", name, obj.Type())
+	}
+	return collect(obj)
+}
@@ -0,0 +1,108 @@
+package evaluator
+
+import (
+	"thecarrionlanguage/object"
+)
+
+// newSet builds a set from elements, dropping duplicates.
+func newSet(elements []object.Object, frozen bool) object.Object {
+	set := object.NewSet()
+	for _, el := range elements {
+		key, ok := object.HashKeyOf(el)
+		if !ok {
+			return newError("unusable as set element: %s", el.Type())
+		}
+		set.Add(key, el)
+	}
+	set.Frozen = frozen
+	return set
+}
+
+// evalSetInfixExpression implements the set algebra operators. The result
+// of a union, intersection or difference is frozen when the left operand is.
+func evalSetInfixExpression(operator string, left, right *object.Set) object.Object {
+	switch operator {
+	case "|":
+		result := copySet(left)
+		for _, key := range right.Keys {
+			result.Add(key, right.Elements[key])
+		}
+		return result
+	case "&":
+		return filterSet(left, func(key object.HashKey) bool { return right.Contains(key) })
+	case "-":
+		return filterSet(left, func(key object.HashKey) bool { return !right.Contains(key) })
+	case "^":
+		result := filterSet(left, func(key object.HashKey) bool { return !right.Contains(key) })
+		for _, key := range right.Keys {
+			if !left.Contains(key) {
+				result.Add(key, right.Elements[key])
+			}
+		}
+		return result
+	case "<=":
+		return nativeBoolToBooleanObject(isSubset(left, right))
+	case "<":
+		return nativeBoolToBooleanObject(len(left.Keys) < len(right.Keys) && isSubset(left, right))
+	case ">=":
+		return nativeBoolToBooleanObject(isSubset(right, left))
+	case ">":
+		return nativeBoolToBooleanObject(len(right.Keys) < len(left.Keys) && isSubset(right, left))
+	case "==":
+		return nativeBoolToBooleanObject(valuesEqual(left, right))
+	case "!=":
+		return nativeBoolToBooleanObject(!valuesEqual(left, right))
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func copySet(set *object.Set) *object.Set {
+	return filterSet(set, func(object.HashKey) bool { return true })
+}
+
+func filterSet(set *object.Set, keep func(object.HashKey) bool) *object.Set {
+	result := object.NewSet()
+	result.Frozen = set.Frozen
+	for _, key := range set.Keys {
+		if keep(key) {
+			result.Add(key, set.Elements[key])
+		}
+	}
+	return result
+}
+
+func isSubset(left, right *object.Set) bool {
+	for _, key := range left.Keys {
+		if !right.Contains(key) {
+			return false
+		}
+	}
+	return true
+}
+
+func setAdd(receiver object.Object, args ...object.Object) object.Object {
+	key, ok := object.HashKeyOf(args[0])
+	if !ok {
+		return newError("unusable as set element: %s", args[0].Type())
+	}
+	receiver.(*object.Set).Add(key, args[0])
+	return NONE
+}
+
+// setRemove deletes an element, failing when it is missing; setDiscard
+// ignores a missing element.
+func setRemove(receiver object.Object, args ...object.Object) object.Object {
+	key, ok := object.HashKeyOf(args[0])
+	if !ok || !receiver.(*object.Set).Remove(key) {
+		return newError("%s is not in set", args[0].Inspect())
+	}
+	return NONE
+}
+
+func setDiscard(receiver object.Object, args ...object.Object) object.Object {
+	if key, ok := object.HashKeyOf(args[0]); ok {
+		receiver.(*object.Set).Remove(key)
+	}
+	return NONE
+}
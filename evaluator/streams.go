@@ -0,0 +1,134 @@
+package evaluator
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"thecarrionlanguage/object"
+)
+
+// printBuiltin writes its arguments separated by sep and followed by end,
+// which default to a space and a newline, to file or else to stdout.
+func (interp *Interpreter) printBuiltin(kwargs map[string]object.Object, args ...object.Object) object.Object {
+	for name := range kwargs {
+		if name != "sep" && name != "end" && name != "file" {
+			return newError("print() got an unexpected keyword argument %s", name)
+		}
+	}
+	sep, err := stringKeyword(kwargs, "sep", " ")
+	if err != nil {
+		return err
+	}
+	end, err := stringKeyword(kwargs, "end", "\n")
+	if err != nil {
+		return err
+	}
+	var out io.Writer = interp.stdout
+	if file, ok := kwargs["file"]; ok && file != NONE {
+		w, ok := file.(io.Writer)
+		if !ok {
+			return newError("file must be a writable stream, got %s", file.Type())
+		}
+		out = w
+	}
+
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		str := toString(arg)
+		if isError(str) {
+			return str
+		}
+		parts[i] = str.(*object.String).Value
+	}
+	if _, err := fmt.Fprint(out, strings.Join(parts, sep), end); err != nil {
+		return newError("print: %s", err)
+	}
+	return NONE
+}
+
+// stringKeyword returns the string passed as the named keyword argument, or
+// def when it is missing or none.
+func stringKeyword(kwargs map[string]object.Object, name, def string) (string, *object.Error) {
+	value, ok := kwargs[name]
+	if !ok || value == NONE {
+		return def, nil
+	}
+	str, ok := value.(*object.String)
+	if !ok {
+		return "", newError("%s must be STRING or none, got %s", name, value.Type())
+	}
+	return str.Value, nil
+}
+
+// inputBuiltin writes the optional prompt to stdout and returns the next
+// line of stdin without its line ending.
+func (interp *Interpreter) inputBuiltin(args ...object.Object) object.Object {
+	if len(args) > 1 {
+		return newError("wrong number of arguments. got=%d, want=0 or 1", len(args))
+	}
+	if len(args) == 1 {
+		prompt := toString(args[0])
+		if isError(prompt) {
+			return prompt
+		}
+		if _, err := fmt.Fprint(interp.stdout, prompt.(*object.String).Value); err != nil {
+			return newError("input: %s", err)
+		}
+	}
+	line, err := interp.stdin.ReadLine()
+	if err == io.EOF {
+		return newError("EOF when reading a line")
+	}
+	if err != nil {
+		return newError("input: %s", err)
+	}
+	line = strings.TrimSuffix(line, "\n")
+	return &object.String{Value: strings.TrimSuffix(line, "\r")}
+}
+
+// textStream is implemented by the objects that the stream methods work
+// on: the standard streams and files.
+type textStream interface {
+	object.Object
+	io.Writer
+	ReadLine() (string, error)
+	ReadAll() (string, error)
+}
+
+// streamReadLine returns the next line with its newline, or "" at the end
+// of the stream.
+func streamReadLine(receiver object.Object, args ...object.Object) object.Object {
+	line, err := receiver.(textStream).ReadLine()
+	if err != nil && err != io.EOF {
+		return newError("readline: %s", err)
+	}
+	return &object.String{Value: line}
+}
+
+func streamReadLines(receiver object.Object, args ...object.Object) object.Object {
+	lines, errObj := collect(receiver)
+	if errObj != nil {
+		return errObj
+	}
+	return &object.Array{Elements: lines}
+}
+
+func streamRead(receiver object.Object, args ...object.Object) object.Object {
+	data, err := receiver.(textStream).ReadAll()
+	if err != nil {
+		return newError("read: %s", err)
+	}
+	return &object.String{Value: data}
+}
+
+func streamWrite(receiver object.Object, args ...object.Object) object.Object {
+	str, errObj := stringArgument("write", args[0])
+	if errObj != nil {
+		return errObj
+	}
+	if _, err := io.WriteString(receiver.(textStream), str.Value); err != nil {
+		return newError("write: %s", err)
+	}
+	return NONE
+}
@@ -16,6 +16,7 @@ type Lexer struct {
 	ch           rune // Current char under examination
 	tokens       []token.Token
 	indentStack  []int // Stack to track indentation levels
+	nesting      int   // Depth of open (), [] and {} pairs
 }
 
 // New initializes a new Lexer with the provided input string.
@@ -51,8 +52,16 @@ func (l *Lexer) peekChar() rune {
 func (l *Lexer) NextToken() token.Token {
 	var tok token.Token
 
+	if len(l.tokens) > 0 {
+		return l.popToken()
+	}
+
 	l.skipWhiteSpace()
 
+	if len(l.tokens) > 0 {
+		return l.popToken()
+	}
+
 	switch l.ch {
 	case '=':
 		if l.peekChar() == '=' {
@@ -68,12 +77,20 @@ func (l *Lexer) NextToken() token.Token {
 	case ':':
 		tok = newToken(token.COLON, l.ch)
 	case '(':
+		l.nesting++
 		tok = newToken(token.LPAREN, l.ch)
 	case ')':
+		if l.nesting > 0 {
+			l.nesting--
+		}
 		tok = newToken(token.RPAREN, l.ch)
 	case '{':
+		l.nesting++
 		tok = newToken(token.LBRACE, l.ch)
 	case '}':
+		if l.nesting > 0 {
+			l.nesting--
+		}
 		tok = newToken(token.RBRACE, l.ch)
 	case '+':
 		if l.peekChar() == '+' {
@@ -90,8 +107,15 @@ func (l *Lexer) NextToken() token.Token {
 			tok = newToken(token.PLUS, l.ch)
 		}
 	case '*':
-		tok = newToken(token.ASTERISK, l.ch)
-		if l.peekChar() == '=' {
+		if l.peekChar() == '*' {
+			l.readChar()
+			if l.peekChar() == '=' {
+				l.readChar()
+				tok = token.Token{Type: token.POWASSGN, Literal: "**="}
+			} else {
+				tok = token.Token{Type: token.POWER, Literal: "**"}
+			}
+		} else if l.peekChar() == '=' {
 			ch := l.ch
 			l.readChar()
 			literal := string(ch) + string(l.ch)
@@ -118,7 +142,15 @@ func (l *Lexer) NextToken() token.Token {
 	case '.':
 		tok = newToken(token.DOT, l.ch)
 	case '/':
-		if l.peekChar() == '=' {
+		if l.peekChar() == '/' {
+			l.readChar()
+			if l.peekChar() == '=' {
+				l.readChar()
+				tok = token.Token{Type: token.FLOORDIVASSGN, Literal: "//="}
+			} else {
+				tok = token.Token{Type: token.FLOOR_DIV, Literal: "//"}
+			}
+		} else if l.peekChar() == '=' {
 			ch := l.ch
 			l.readChar()
 			literal := string(ch) + string(l.ch)
@@ -129,13 +161,47 @@ func (l *Lexer) NextToken() token.Token {
 	case '%':
 		tok = newToken(token.MOD, l.ch)
 	case '[':
+		l.nesting++
 		tok = newToken(token.LBRACK, l.ch)
 	case ']':
+		if l.nesting > 0 {
+			l.nesting--
+		}
 		tok = newToken(token.RBRACK, l.ch)
 	case '|':
-		tok = newToken(token.PIPE, l.ch)
+		if l.peekChar() == '>' {
+			ch := l.ch
+			l.readChar()
+			literal := string(ch) + string(l.ch)
+			tok = token.Token{Type: token.PIPELINE, Literal: literal}
+		} else if l.peekChar() == '=' {
+			ch := l.ch
+			l.readChar()
+			literal := string(ch) + string(l.ch)
+			tok = token.Token{Type: token.ORASSGN, Literal: literal}
+		} else {
+			tok = newToken(token.PIPE, l.ch)
+		}
 	case '&':
-		tok = newToken(token.AMPERSAND, l.ch)
+		if l.peekChar() == '=' {
+			ch := l.ch
+			l.readChar()
+			literal := string(ch) + string(l.ch)
+			tok = token.Token{Type: token.ANDASSGN, Literal: literal}
+		} else {
+			tok = newToken(token.AMPERSAND, l.ch)
+		}
+	case '^':
+		if l.peekChar() == '=' {
+			ch := l.ch
+			l.readChar()
+			literal := string(ch) + string(l.ch)
+			tok = token.Token{Type: token.XORASSGN, Literal: literal}
+		} else {
+			tok = newToken(token.CARET, l.ch)
+		}
+	case '~':
+		tok = newToken(token.TILDE, l.ch)
 	case '#':
 		tok = newToken(token.HASH, l.ch)
 	case '@':
@@ -152,7 +218,15 @@ func (l *Lexer) NextToken() token.Token {
 			tok = newToken(token.BANG, l.ch)
 		}
 	case '<':
-		if l.peekChar() == '=' {
+		if l.peekChar() == '<' {
+			l.readChar()
+			if l.peekChar() == '=' {
+				l.readChar()
+				tok = token.Token{Type: token.LSHIFTASSGN, Literal: "<<="}
+			} else {
+				tok = token.Token{Type: token.LSHIFT, Literal: "<<"}
+			}
+		} else if l.peekChar() == '=' {
 			ch := l.ch
 			l.readChar()
 			literal := string(ch) + string(l.ch)
@@ -161,7 +235,15 @@ func (l *Lexer) NextToken() token.Token {
 			tok = newToken(token.LT, l.ch)
 		}
 	case '>':
-		if l.peekChar() == '=' {
+		if l.peekChar() == '>' {
+			l.readChar()
+			if l.peekChar() == '=' {
+				l.readChar()
+				tok = token.Token{Type: token.RSHIFTASSGN, Literal: ">>="}
+			} else {
+				tok = token.Token{Type: token.RSHIFT, Literal: ">>"}
+			}
+		} else if l.peekChar() == '=' {
 			ch := l.ch
 			l.readChar()
 			literal := string(ch) + string(l.ch)
@@ -202,9 +284,10 @@ func (l *Lexer) NextToken() token.Token {
 }
 
 // skipWhiteSpace skips over spaces, tabs, and handles newlines for indentation.
+// Newlines inside brackets are plain whitespace so literals may span lines.
 func (l *Lexer) skipWhiteSpace() {
 	for l.ch == ' ' || l.ch == '\t' || l.ch == '\r' || l.ch == '\n' {
-		if l.ch == '\n' {
+		if l.ch == '\n' && l.nesting == 0 {
 			l.emitNewline()
 			l.readChar()
 			l.handleIndentation()
@@ -256,6 +339,13 @@ func (l *Lexer) readString() string {
 	}
 }
 
+// popToken returns the oldest queued layout token.
+func (l *Lexer) popToken() token.Token {
+	tok := l.tokens[0]
+	l.tokens = l.tokens[1:]
+	return tok
+}
+
 // emitNewline emits a NEWLINE token.
 func (l *Lexer) emitNewline() {
 	tok := token.Token{Type: token.NEWLINE, Literal: "\n"}
@@ -276,6 +366,11 @@ func (l *Lexer) handleIndentation() {
 		l.readChar()
 	}
 
+	// Blank lines don't change the indentation level
+	if l.ch == '\n' || l.ch == '\r' || l.ch == 0 {
+		return
+	}
+
 	indentString := l.input[startPos:l.position]
 	currentIndent := len(indentString)
 
@@ -311,7 +406,7 @@ func (l *Lexer) emitIndentToken(tokenType token.TokenType) {
 // readIdentifier reads an identifier starting with a letter or underscore.
 func (l *Lexer) readIdentifier() string {
 	start := l.position
-	for isLetter(l.ch) {
+	for isLetter(l.ch) || isDigit(l.ch) {
 		l.readChar()
 	}
 	return l.input[start:l.position]
@@ -28,11 +28,14 @@ func TestNextToken(t *testing.T) {
 		{token.IDENT, "five"},
 		{token.ASSIGN, "="},
 		{token.INT, "5"},
+		{token.NEWLINE, "\n"},
 
 		// Line 2: two spaces + ten = 10
+		{token.INDENT, ""},
 		{token.IDENT, "ten"},
 		{token.ASSIGN, "="},
 		{token.INT, "10"},
+		{token.NEWLINE, "\n"},
 		// Line 3: two spaces + spell add(x , y):
 		{token.SPELL, "spell"},
 		{token.IDENT, "add"},
@@ -42,11 +45,17 @@ func TestNextToken(t *testing.T) {
 		{token.IDENT, "y"},
 		{token.RPAREN, ")"},
 		{token.COLON, ":"},
+		{token.NEWLINE, "\n"},
 		// Line 4: four spaces + return x + y
+		{token.INDENT, ""},
 		{token.RETURN, "return"},
 		{token.IDENT, "x"},
 		{token.PLUS, "+"},
 		{token.IDENT, "y"},
+		{token.NEWLINE, "\n"},
+		// Line 5: blank line, then dedent back to two spaces
+		{token.NEWLINE, "\n"},
+		{token.DEDENT, ""},
 		// Line 6: two spaces + result = add(five, ten)
 		{token.IDENT, "result"},
 		{token.ASSIGN, "="},
@@ -56,18 +65,23 @@ func TestNextToken(t *testing.T) {
 		{token.COMMA, ","},
 		{token.IDENT, "ten"},
 		{token.RPAREN, ")"},
+		{token.NEWLINE, "\n"},
 
 		// result greater than or equal to 16
 		{token.IDENT, "result"},
 		{token.GE, ">="},
 		{token.INT, "16"},
+		{token.NEWLINE, "\n"},
 		{token.STRING, "foobar"},
+		{token.NEWLINE, "\n"},
 		{token.STRING, "foo bar"},
+		{token.NEWLINE, "\n"},
 		{token.LBRACK, "["},
 		{token.INT, "1"},
 		{token.COMMA, ","},
 		{token.INT, "2"},
 		{token.RBRACK, "]"},
+		{token.NEWLINE, "\n"},
 		// End of input: dedent to base and EOF
 		{token.DEDENT, ""},
 		{token.EOF, ""},
@@ -95,3 +109,96 @@ func TestNextToken(t *testing.T) {
 		}
 	}
 }
+
+func TestNextTokenOperators(t *testing.T) {
+	input := `x |> f | g & h ^ ~i << 2 >> 1 ** 3 // 4
+x **= 2 //= 3 &= 4 |= 5 ^= 6 <<= 7 >>= 8 *= 9 /= 10`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "x"},
+		{token.PIPELINE, "|>"},
+		{token.IDENT, "f"},
+		{token.PIPE, "|"},
+		{token.IDENT, "g"},
+		{token.AMPERSAND, "&"},
+		{token.IDENT, "h"},
+		{token.CARET, "^"},
+		{token.TILDE, "~"},
+		{token.IDENT, "i"},
+		{token.LSHIFT, "<<"},
+		{token.INT, "2"},
+		{token.RSHIFT, ">>"},
+		{token.INT, "1"},
+		{token.POWER, "**"},
+		{token.INT, "3"},
+		{token.FLOOR_DIV, "//"},
+		{token.INT, "4"},
+		{token.NEWLINE, "\n"},
+		{token.IDENT, "x"},
+		{token.POWASSGN, "**="},
+		{token.INT, "2"},
+		{token.FLOORDIVASSGN, "//="},
+		{token.INT, "3"},
+		{token.ANDASSGN, "&="},
+		{token.INT, "4"},
+		{token.ORASSGN, "|="},
+		{token.INT, "5"},
+		{token.XORASSGN, "^="},
+		{token.INT, "6"},
+		{token.LSHIFTASSGN, "<<="},
+		{token.INT, "7"},
+		{token.RSHIFTASSGN, ">>="},
+		{token.INT, "8"},
+		{token.MULTASSGN, "*="},
+		{token.INT, "9"},
+		{token.DIVASSGN, "/="},
+		{token.INT, "10"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Errorf("tests[%d] - Token Type Wrong. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Errorf("tests[%d] - Token Literal Wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestNextTokenIdentifiersWithDigits(t *testing.T) {
+	input := `atan2(y1, x_2) 3d`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "atan2"},
+		{token.LPAREN, "("},
+		{token.IDENT, "y1"},
+		{token.COMMA, ","},
+		{token.IDENT, "x_2"},
+		{token.RPAREN, ")"},
+		{token.INT, "3"},
+		{token.IDENT, "d"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Errorf("tests[%d] - wrong token. expected=%q %q, got=%q %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 
@@ -26,10 +27,13 @@ const CROW_IMAGE = `
   `
 
 func main() {
-	if len(os.Args) > 1 {
-		repl.Start(os.Stdin, os.Stdout)
+	noAssert := flag.Bool("no-assert", false, "skip assert statements")
+	flag.Parse()
+
+	if flag.NArg() > 0 {
+		repl.Start(os.Stdin, os.Stdout, !*noAssert)
 	} else {
 		fmt.Printf("%s\n", CROW_IMAGE)
-		repl.Start(os.Stdin, os.Stdout)
+		repl.Start(os.Stdin, os.Stdout, !*noAssert)
 	}
 }
@@ -0,0 +1,34 @@
+// Package munin holds the Carrion standard library modules that are written
+// in Carrion itself. They are embedded into the interpreter, so importing
+// them never touches the filesystem.
+package munin
+
+import (
+	"embed"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+//go:embed *.crl
+var files embed.FS
+
+// Source returns the source of the module called name.
+func Source(name string) (string, bool) {
+	data, err := files.ReadFile(name + ".crl")
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// Modules lists the names of the embedded modules.
+func Modules() []string {
+	entries, _ := fs.ReadDir(files, ".")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".crl"))
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,32 @@
+package munin
+
+import (
+	"testing"
+
+	"thecarrionlanguage/lexer"
+	"thecarrionlanguage/parser"
+)
+
+func TestModulesParse(t *testing.T) {
+	names := Modules()
+	if len(names) == 0 {
+		t.Fatalf("no modules embedded")
+	}
+	for _, name := range names {
+		source, ok := Source(name)
+		if !ok {
+			t.Fatalf("module %s listed but not found", name)
+		}
+		p := parser.New(lexer.New(source))
+		p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Errorf("module %s has parser errors: %q", name, p.Errors())
+		}
+	}
+}
+
+func TestMissingModule(t *testing.T) {
+	if _, ok := Source("no_such_module"); ok {
+		t.Errorf("found a module that does not exist")
+	}
+}
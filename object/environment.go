@@ -1,8 +1,14 @@
 package object
 
+import "sort"
+
 type Environment struct {
-	store map[string]Object
-	outer *Environment
+	store  map[string]Object
+	consts map[string]bool         // Names in store that were bound with const
+	refs   map[string]*Environment // Names declared global or nonlocal, and the scope they live in
+	outer  *Environment
+	yield  func(Object) bool // Set on the environment of a running generator
+	host   interface{}       // Set on a global environment by the interpreter running it
 }
 
 func NewEnvironment() *Environment {
@@ -20,6 +26,9 @@ func NewEnclosedEnvironment(outer *Environment) *Environment {
 }
 
 func (e *Environment) Get(name string) (Object, bool) {
+	if target, ok := e.refs[name]; ok {
+		return target.Get(name)
+	}
 	obj, ok := e.store[name]
 	if !ok && e.outer != nil {
 		return e.outer.Get(name)
@@ -27,7 +36,118 @@ func (e *Environment) Get(name string) (Object, bool) {
 	return obj, ok
 }
 
+// Set binds name in this scope, or in the scope a global or nonlocal
+// declaration points it at.
 func (e *Environment) Set(name string, val Object) Object {
+	if target, ok := e.refs[name]; ok {
+		return target.Set(name, val)
+	}
 	e.store[name] = val
 	return val
 }
+
+// Declare binds name in this scope regardless of any global or nonlocal
+// declaration, marking it constant if requested.
+func (e *Environment) Declare(name string, val Object, constant bool) {
+	delete(e.refs, name)
+	e.store[name] = val
+	if constant {
+		if e.consts == nil {
+			e.consts = make(map[string]bool)
+		}
+		e.consts[name] = true
+	} else {
+		delete(e.consts, name)
+	}
+}
+
+// Names returns the names bound in this scope, in alphabetical order.
+func (e *Environment) Names() []string {
+	names := make([]string, 0, len(e.store))
+	for name := range e.store {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Refer makes name in this scope stand for the binding in target, as a
+// global or nonlocal declaration does.
+func (e *Environment) Refer(name string, target *Environment) {
+	if e.refs == nil {
+		e.refs = make(map[string]*Environment)
+	}
+	e.refs[name] = target
+}
+
+// Owner returns the scope that holds the binding name currently resolves
+// to, or nil when name is unbound.
+func (e *Environment) Owner(name string) *Environment {
+	if target, ok := e.refs[name]; ok {
+		return target.Owner(name)
+	}
+	if _, ok := e.store[name]; ok {
+		return e
+	}
+	if e.outer != nil {
+		return e.outer.Owner(name)
+	}
+	return nil
+}
+
+// IsConst reports whether name currently resolves to a constant.
+func (e *Environment) IsConst(name string) bool {
+	owner := e.Owner(name)
+	return owner != nil && owner.consts[name]
+}
+
+// SetsConstant reports whether Set(name) would overwrite a constant: a
+// constant in this scope, or in the scope a global or nonlocal declaration
+// points name at. A constant in an outer scope is shadowed, not overwritten.
+func (e *Environment) SetsConstant(name string) bool {
+	if target, ok := e.refs[name]; ok {
+		return target.SetsConstant(name)
+	}
+	return e.consts[name]
+}
+
+// HasLocal reports whether name is bound in this scope itself.
+func (e *Environment) HasLocal(name string) bool {
+	_, ok := e.store[name]
+	return ok
+}
+
+func (e *Environment) Outer() *Environment {
+	return e.outer
+}
+
+// Global returns the outermost scope.
+func (e *Environment) Global() *Environment {
+	if e.outer == nil {
+		return e
+	}
+	return e.outer.Global()
+}
+
+// SetHost attaches the state of the interpreter that runs this environment.
+func (e *Environment) SetHost(host interface{}) {
+	e.host = host
+}
+
+// Host returns what SetHost attached to the outermost scope, or nil.
+func (e *Environment) Host() interface{} {
+	return e.Global().host
+}
+
+// SetYield marks the environment as the body of a running generator.
+func (e *Environment) SetYield(yield func(Object) bool) {
+	e.yield = yield
+}
+
+// Yield returns the yield callback of the innermost enclosing generator.
+func (e *Environment) Yield() (func(Object) bool, bool) {
+	if e.yield == nil && e.outer != nil {
+		return e.outer.Yield()
+	}
+	return e.yield, e.yield != nil
+}
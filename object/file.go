@@ -0,0 +1,127 @@
+package object
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// File is a file opened by open(). It reads and writes like a Stream, and
+// as a ContextManager it closes itself at the end of a with statement.
+type File struct {
+	Stream
+	Path   string
+	Mode   string
+	handle *os.File
+	closed bool
+}
+
+// fileModes maps the modes accepted by OpenFile to os.OpenFile flags.
+var fileModes = map[string]int{
+	"r":  os.O_RDONLY,
+	"w":  os.O_WRONLY | os.O_CREATE | os.O_TRUNC,
+	"a":  os.O_WRONLY | os.O_CREATE | os.O_APPEND,
+	"x":  os.O_WRONLY | os.O_CREATE | os.O_EXCL,
+	"r+": os.O_RDWR,
+	"w+": os.O_RDWR | os.O_CREATE | os.O_TRUNC,
+	"a+": os.O_RDWR | os.O_CREATE | os.O_APPEND,
+	"x+": os.O_RDWR | os.O_CREATE | os.O_EXCL,
+}
+
+// OpenFile opens path in one of the modes r, w, a and x, optionally
+// followed by + to allow both reading and writing.
+func OpenFile(path, mode string) (*File, error) {
+	flags, ok := fileModes[mode]
+	if !ok {
+		return nil, fmt.Errorf("invalid mode: %q", mode)
+	}
+	handle, err := os.OpenFile(path, flags, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	f := &File{Stream: Stream{Name: path}, Path: path, Mode: mode, handle: handle}
+	if mode == "r" || strings.HasSuffix(mode, "+") {
+		f.Reader = bufio.NewReader(handle)
+	}
+	if mode != "r" {
+		f.Writer = handle
+	}
+	return f, nil
+}
+
+func (f *File) Type() ObjectType { return FILE_OBJ }
+func (f *File) Inspect() string  { return fmt.Sprintf("<file %s (%s)>", f.Path, f.Mode) }
+
+// usable fails once the file is closed, and when the file was not opened
+// for the operation.
+func (f *File) usable(operation string, allowed bool) error {
+	if f.closed {
+		return fmt.Errorf("I/O operation on closed file %s", f.Path)
+	}
+	if !allowed {
+		return fmt.Errorf("file %s is not open for %s", f.Path, operation)
+	}
+	return nil
+}
+
+func (f *File) Write(p []byte) (int, error) {
+	if err := f.usable("writing", f.Writer != nil); err != nil {
+		return 0, err
+	}
+	if err := f.discardReadAhead(); err != nil {
+		return 0, err
+	}
+	return f.Stream.Write(p)
+}
+
+// discardReadAhead moves the handle back over input that the reader has
+// buffered but not returned, so that a write after a read lands right after
+// the data that was read.
+func (f *File) discardReadAhead() error {
+	if f.Reader == nil || f.Reader.Buffered() == 0 {
+		return nil
+	}
+	if _, err := f.handle.Seek(-int64(f.Reader.Buffered()), io.SeekCurrent); err != nil {
+		return err
+	}
+	f.Reader.Reset(f.handle)
+	return nil
+}
+
+func (f *File) ReadLine() (string, error) {
+	if err := f.usable("reading", f.Reader != nil); err != nil {
+		return "", err
+	}
+	return f.Stream.ReadLine()
+}
+
+func (f *File) ReadAll() (string, error) {
+	if err := f.usable("reading", f.Reader != nil); err != nil {
+		return "", err
+	}
+	return f.Stream.ReadAll()
+}
+
+// Iter yields the remaining lines of the file, each with its newline.
+func (f *File) Iter() Iterator { return &lineIterator{source: f} }
+
+// Close closes the file. Closing it again does nothing.
+func (f *File) Close() error {
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	return f.handle.Close()
+}
+
+func (f *File) Enter() Object { return f }
+
+// Exit closes the file without suppressing the error that ended the body.
+func (f *File) Exit(err *Error) (bool, *Error) {
+	if closeErr := f.Close(); closeErr != nil {
+		return false, &Error{Message: closeErr.Error()}
+	}
+	return false, nil
+}
@@ -0,0 +1,106 @@
+package object
+
+import "iter"
+
+// Iterator hands out the elements of an iterable one at a time. Errors raised
+// while producing an element are returned as the element itself.
+type Iterator interface {
+	Next() (Object, bool)
+}
+
+// Iterable is implemented by every object that `for` loops and builtins such
+// as list() can consume.
+type Iterable interface {
+	Iter() Iterator
+}
+
+type sliceIterator struct {
+	elements []Object
+	index    int
+}
+
+func (si *sliceIterator) Next() (Object, bool) {
+	if si.index >= len(si.elements) {
+		return nil, false
+	}
+	elem := si.elements[si.index]
+	si.index++
+	return elem, true
+}
+
+// arrayIterator walks an array by position, so elements appended while
+// looping are visited too.
+type arrayIterator struct {
+	array *Array
+	index int
+}
+
+func (ai *arrayIterator) Next() (Object, bool) {
+	if ai.index >= len(ai.array.Elements) {
+		return nil, false
+	}
+	elem := ai.array.Elements[ai.index]
+	ai.index++
+	return elem, true
+}
+
+func (ao *Array) Iter() Iterator { return &arrayIterator{array: ao} }
+func (t *Tuple) Iter() Iterator  { return &sliceIterator{elements: t.Elements} }
+
+// Iter yields each character of the string as a one-character string.
+func (s *String) Iter() Iterator {
+	chars := []Object{}
+	for _, ch := range s.Value {
+		chars = append(chars, &String{Value: string(ch)})
+	}
+	return &sliceIterator{elements: chars}
+}
+
+// Iter yields the keys of the hash in insertion order.
+func (h *Hash) Iter() Iterator {
+	keys := make([]Object, 0, len(h.Keys))
+	for _, pair := range h.OrderedPairs() {
+		keys = append(keys, pair.Key)
+	}
+	return &sliceIterator{elements: keys}
+}
+
+// Generator is the lazy sequence returned by calling a spell that contains
+// `yield`. The spell body runs as a coroutine and is suspended at each yield
+// until the next value is requested.
+type Generator struct {
+	next    func() (Object, bool)
+	stop    func()
+	running bool // Set while the body runs, which it must not do twice at once
+}
+
+func NewGenerator(seq iter.Seq[Object]) *Generator {
+	next, stop := iter.Pull(seq)
+	return &Generator{next: next, stop: stop}
+}
+
+func (g *Generator) Type() ObjectType { return GENERATOR_OBJ }
+func (g *Generator) Inspect() string  { return "generator" }
+
+// Next resumes the body until its next yield. Asking a generator for a value
+// from inside its own body fails instead of resuming it a second time.
+func (g *Generator) Next() (Object, bool) {
+	if g.running {
+		return &Error{Message: "generator already executing"}, true
+	}
+	g.running = true
+	defer func() { g.running = false }()
+	return g.next()
+}
+
+// Stop abandons the generator, unwinding its suspended body. It does nothing
+// while the body is running, since the body can only be unwound once it
+// suspends.
+func (g *Generator) Stop() {
+	if !g.running {
+		g.stop()
+	}
+}
+
+// Iter returns the generator itself; a generator can only be consumed once.
+func (g *Generator) Iter() Iterator { return g }
@@ -0,0 +1,65 @@
+package object
+
+import (
+	"errors"
+	"sync"
+)
+
+// Lock is a mutual exclusion lock made by lock(). As a ContextManager it is
+// held for the duration of a with statement.
+type Lock struct {
+	mu sync.Mutex
+}
+
+func (l *Lock) Type() ObjectType { return LOCK_OBJ }
+func (l *Lock) Inspect() string {
+	if l.Locked() {
+		return "<lock locked>"
+	}
+	return "<lock unlocked>"
+}
+
+// Acquire takes the lock. The interpreter runs a single thread, so nothing
+// could ever release a lock that is already held; acquiring it again fails
+// instead of waiting forever.
+func (l *Lock) Acquire() error {
+	if !l.mu.TryLock() {
+		return errors.New("lock is already held")
+	}
+	return nil
+}
+
+// Release frees the lock, failing if it is not held.
+func (l *Lock) Release() error {
+	if l.mu.TryLock() {
+		l.mu.Unlock()
+		return errors.New("release of unlocked lock")
+	}
+	l.mu.Unlock()
+	return nil
+}
+
+// Locked reports whether the lock is held.
+func (l *Lock) Locked() bool {
+	if l.mu.TryLock() {
+		l.mu.Unlock()
+		return false
+	}
+	return true
+}
+
+// Enter acquires the lock, returning an Error if it is already held.
+func (l *Lock) Enter() Object {
+	if err := l.Acquire(); err != nil {
+		return &Error{Message: err.Error()}
+	}
+	return l
+}
+
+// Exit releases the lock without suppressing the error that ended the body.
+func (l *Lock) Exit(err *Error) (bool, *Error) {
+	if releaseErr := l.Release(); releaseErr != nil {
+		return false, &Error{Message: releaseErr.Error()}
+	}
+	return false, nil
+}
@@ -0,0 +1,11 @@
+package object
+
+// Module is an imported module. Its members are the names bound at the top
+// level of Env.
+type Module struct {
+	Name string
+	Env  *Environment
+}
+
+func (m *Module) Type() ObjectType { return MODULE_OBJ }
+func (m *Module) Inspect() string  { return "<module " + m.Name + ">" }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"hash/fnv"
+	"math"
 	"strings"
 
 	"thecarrionlanguage/ast"
@@ -29,6 +30,14 @@ const (
 	BUILTIN_OBJ      = "BUILTIN"
 	HASH_OBJ         = "HASH"
 	TUPLE_OBJ        = "TUPLE"
+	GENERATOR_OBJ    = "GENERATOR"
+	SET_OBJ          = "SET"
+	FROZENSET_OBJ    = "FROZENSET"
+	RANGE_OBJ        = "RANGE"
+	STREAM_OBJ       = "STREAM"
+	MODULE_OBJ       = "MODULE"
+	FILE_OBJ         = "FILE"
+	LOCK_OBJ         = "LOCK"
 )
 
 type Integer struct {
@@ -74,9 +83,10 @@ func (e *Error) Type() ObjectType { return ERROR_OBJ }
 func (e *Error) Inspect() string  { return "ERROR: " + e.Message }
 
 type Function struct {
-	Parameters []*ast.Identifier
-	Body       *ast.BlockStatement
-	Env        *Environment
+	Parameters  []*ast.Identifier
+	Body        *ast.BlockStatement
+	Env         *Environment
+	IsGenerator bool // The body contains `yield`
 }
 
 func (f *Function) Inspect() string {
@@ -105,8 +115,19 @@ func (s *String) Inspect() string  { return s.Value }
 
 type BuiltinFunction func(args ...Object) Object
 
+// KeywordBuiltinFunction is a builtin that also receives the keyword
+// arguments of the call, keyed by name.
+type KeywordBuiltinFunction func(kwargs map[string]Object, args ...Object) Object
+
 type Builtin struct {
-	Fn BuiltinFunction
+	Name string // The name it is called by, used in error messages
+	Fn   BuiltinFunction
+	// KwFn, when set, is called instead of Fn. Builtins without it reject
+	// keyword arguments.
+	KwFn KeywordBuiltinFunction
+	// PipeArg is the position the pipeline operator inserts its left operand
+	// at, so `xs |> map(f)` calls map(f, xs). Zero means the first argument.
+	PipeArg int
 }
 
 func (b *Builtin) Type() ObjectType { return BUILTIN_OBJ }
@@ -132,6 +153,9 @@ func (ao *Array) Inspect() string {
 type HashKey struct {
 	Type  ObjectType
 	Value uint64
+	// Text holds the exact key for types whose Value is only a hash, so
+	// that two keys with colliding hashes never share a slot in Hash.Pairs.
+	Text string
 }
 
 func (b *Boolean) HashKey() HashKey {
@@ -151,7 +175,62 @@ func (i *Integer) HashKey() HashKey {
 func (s *String) HashKey() HashKey {
 	h := fnv.New64a()
 	h.Write([]byte(s.Value))
-	return HashKey{Type: s.Type(), Value: h.Sum64()}
+	return HashKey{Type: s.Type(), Value: h.Sum64(), Text: s.Value}
+}
+
+// HashKey gives floats holding a whole number the key of the equal integer,
+// so that 1 and 1.0 address the same entry.
+func (f *Float) HashKey() HashKey {
+	if f.Value == math.Trunc(f.Value) && f.Value >= math.MinInt64 && f.Value < math.MaxInt64 {
+		return HashKey{Type: INTEGER_OBJ, Value: uint64(int64(f.Value))}
+	}
+	return HashKey{Type: f.Type(), Value: math.Float64bits(f.Value)}
+}
+
+// HashKey for a tuple combines the keys of its elements. Only tuples whose
+// elements are all hashable can be used as keys; check with HashKeyOf.
+func (t *Tuple) HashKey() HashKey {
+	key, _ := t.hashKey()
+	return key
+}
+
+func (t *Tuple) hashKey() (HashKey, bool) {
+	var text strings.Builder
+	for _, e := range t.Elements {
+		key, ok := HashKeyOf(e)
+		if !ok {
+			return HashKey{}, false
+		}
+		text.WriteString(key.encode())
+	}
+	h := fnv.New64a()
+	h.Write([]byte(text.String()))
+	return HashKey{Type: t.Type(), Value: h.Sum64(), Text: text.String()}, true
+}
+
+// encode spells out a key unambiguously, for building the keys of
+// containers from the keys of their elements.
+func (k HashKey) encode() string {
+	return fmt.Sprintf("%s:%d:%q,", k.Type, k.Value, k.Text)
+}
+
+// HashKeyOf returns the hash key of obj. ok is false when obj cannot be
+// used as a key, including tuples that hold an unhashable element and sets
+// that are not frozen.
+func HashKeyOf(obj Object) (key HashKey, ok bool) {
+	switch obj := obj.(type) {
+	case *Tuple:
+		return obj.hashKey()
+	case *Set:
+		if !obj.Frozen {
+			return HashKey{}, false
+		}
+	}
+	hashable, ok := obj.(Hashable)
+	if !ok {
+		return HashKey{}, false
+	}
+	return hashable.HashKey(), true
 }
 
 type HashPair struct {
@@ -160,13 +239,39 @@ type HashPair struct {
 }
 type Hash struct {
 	Pairs map[HashKey]HashPair
+	Keys  []HashKey // Keys of Pairs in insertion order
+}
+
+func NewHash() *Hash {
+	return &Hash{Pairs: make(map[HashKey]HashPair)}
+}
+
+// Set stores pair under key. A key that is already present keeps its
+// original position and its original key object, as in Python, so only the
+// value changes.
+func (h *Hash) Set(key HashKey, pair HashPair) {
+	if existing, ok := h.Pairs[key]; ok {
+		pair.Key = existing.Key
+	} else {
+		h.Keys = append(h.Keys, key)
+	}
+	h.Pairs[key] = pair
+}
+
+// OrderedPairs returns the pairs of the hash in insertion order.
+func (h *Hash) OrderedPairs() []HashPair {
+	pairs := make([]HashPair, 0, len(h.Keys))
+	for _, key := range h.Keys {
+		pairs = append(pairs, h.Pairs[key])
+	}
+	return pairs
 }
 
 func (h *Hash) Type() ObjectType { return HASH_OBJ }
 func (h *Hash) Inspect() string {
 	var out bytes.Buffer
 	pairs := []string{}
-	for _, pair := range h.Pairs {
+	for _, pair := range h.OrderedPairs() {
 		pairs = append(pairs, fmt.Sprintf("%s: %s",
 			pair.Key.Inspect(), pair.Value.Inspect()))
 	}
@@ -180,6 +285,26 @@ type Hashable interface {
 	HashKey() HashKey
 }
 
+// ContextManager is implemented by builtin objects that can be used in a
+// with statement. Enter returns the value bound by `as`, or an Error when the
+// object cannot be entered, in which case the body and Exit do not run. Exit
+// receives the error that ended the body, or nil; it reports whether that
+// error should be suppressed and may fail with an error of its own.
+type ContextManager interface {
+	Object
+	Enter() Object
+	Exit(err *Error) (suppress bool, failure *Error)
+}
+
+// Instance is an object that can override operators and builtins with
+// special spells, such as `__add__` or `__len__`; spellbook instances are
+// the intended implementers. Method returns the spell registered under name,
+// already bound to the instance so it is called without a self argument.
+type Instance interface {
+	Object
+	Method(name string) (Object, bool)
+}
+
 type Tuple struct {
 	Elements []Object
 }
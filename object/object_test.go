@@ -17,3 +17,72 @@ func TestStringHashKey(t *testing.T) {
 		t.Errorf("strings with different content have same hash keys")
 	}
 }
+
+func TestFloatHashKey(t *testing.T) {
+	if (&Float{Value: 1.0}).HashKey() != (&Integer{Value: 1}).HashKey() {
+		t.Errorf("whole float and equal integer have different hash keys")
+	}
+	if (&Float{Value: 1.5}).HashKey() != (&Float{Value: 1.5}).HashKey() {
+		t.Errorf("floats with same value have different hash keys")
+	}
+	if (&Float{Value: 1.5}).HashKey() == (&Float{Value: 2.5}).HashKey() {
+		t.Errorf("floats with different values have same hash keys")
+	}
+}
+
+func TestTupleHashKey(t *testing.T) {
+	tuple := func(elements ...Object) *Tuple { return &Tuple{Elements: elements} }
+	point1 := tuple(&Integer{Value: 1}, &String{Value: "a"})
+	point2 := tuple(&Float{Value: 1}, &String{Value: "a"})
+	other := tuple(&Integer{Value: 1}, &String{Value: "b"})
+	nested := tuple(tuple(&Integer{Value: 1}), &String{Value: "a"})
+
+	if point1.HashKey() != point2.HashKey() {
+		t.Errorf("tuples with equal elements have different hash keys")
+	}
+	if point1.HashKey() == other.HashKey() || point1.HashKey() == nested.HashKey() {
+		t.Errorf("tuples with different elements have same hash keys")
+	}
+	if _, ok := HashKeyOf(tuple(&Integer{Value: 1}, &Array{})); ok {
+		t.Errorf("tuple holding an array should not be hashable")
+	}
+}
+
+func TestHashKeyCollisions(t *testing.T) {
+	// Two keys sharing a hash value must still be distinct map keys.
+	a := (&String{Value: "a"}).HashKey()
+	b := a
+	b.Text = "b"
+	pairs := map[HashKey]HashPair{
+		a: {Key: &String{Value: "a"}, Value: &Integer{Value: 1}},
+		b: {Key: &String{Value: "b"}, Value: &Integer{Value: 2}},
+	}
+	if len(pairs) != 2 {
+		t.Errorf("colliding keys overwrote each other")
+	}
+}
+
+func TestRange(t *testing.T) {
+	tests := []struct {
+		rng      *Range
+		length   int64
+		member   int64
+		contains bool
+	}{
+		{&Range{Start: 0, Stop: 10, Step: 1}, 10, 9, true},
+		{&Range{Start: 0, Stop: 10, Step: 3}, 4, 9, true},
+		{&Range{Start: 0, Stop: 10, Step: 3}, 4, 10, false},
+		{&Range{Start: 10, Stop: 0, Step: -2}, 5, 2, true},
+		{&Range{Start: 10, Stop: 0, Step: -2}, 5, 0, false},
+		{&Range{Start: 5, Stop: 5, Step: 1}, 0, 5, false},
+		{&Range{Start: 5, Stop: 0, Step: 1}, 0, 3, false},
+	}
+	for _, tt := range tests {
+		if got := tt.rng.Len(); got != tt.length {
+			t.Errorf("%s has wrong length. expected=%d, got=%d", tt.rng.Inspect(), tt.length, got)
+		}
+		if got := tt.rng.Contains(tt.member); got != tt.contains {
+			t.Errorf("%s contains %d: expected=%t, got=%t", tt.rng.Inspect(), tt.member, tt.contains, got)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package object
+
+import "fmt"
+
+// Range is the lazy integer sequence returned by range(). Its elements are
+// computed on demand, so even a very long range takes constant space.
+type Range struct {
+	Start, Stop, Step int64
+}
+
+func (r *Range) Type() ObjectType { return RANGE_OBJ }
+func (r *Range) Inspect() string {
+	if r.Step == 1 {
+		return fmt.Sprintf("range(%d, %d)", r.Start, r.Stop)
+	}
+	return fmt.Sprintf("range(%d, %d, %d)", r.Start, r.Stop, r.Step)
+}
+
+// Len returns the number of elements in the range.
+func (r *Range) Len() int64 {
+	switch {
+	case r.Step > 0 && r.Start < r.Stop:
+		return (r.Stop-r.Start-1)/r.Step + 1
+	case r.Step < 0 && r.Start > r.Stop:
+		return (r.Start-r.Stop-1)/-r.Step + 1
+	}
+	return 0
+}
+
+// At returns the element at position i, which is not checked against the
+// length of the range.
+func (r *Range) At(i int64) int64 {
+	return r.Start + i*r.Step
+}
+
+// Contains reports whether v is one of the elements of the range.
+func (r *Range) Contains(v int64) bool {
+	if r.Step > 0 && (v < r.Start || v >= r.Stop) {
+		return false
+	}
+	if r.Step < 0 && (v > r.Start || v <= r.Stop) {
+		return false
+	}
+	return (v-r.Start)%r.Step == 0
+}
+
+type rangeIterator struct {
+	rng   *Range
+	index int64
+}
+
+func (ri *rangeIterator) Next() (Object, bool) {
+	if ri.index >= ri.rng.Len() {
+		return nil, false
+	}
+	elem := &Integer{Value: ri.rng.At(ri.index)}
+	ri.index++
+	return elem, true
+}
+
+func (r *Range) Iter() Iterator { return &rangeIterator{rng: r} }
@@ -0,0 +1,102 @@
+package object
+
+import (
+	"hash/fnv"
+	"sort"
+	"strings"
+)
+
+// Set is a collection of distinct hashable values. Elements are kept in
+// insertion order so that printing and iteration are deterministic. A
+// frozen set never changes, which lets it be a hash key or a set element.
+type Set struct {
+	Elements map[HashKey]Object
+	Keys     []HashKey // Keys of Elements in insertion order
+	Frozen   bool
+}
+
+func NewSet() *Set {
+	return &Set{Elements: make(map[HashKey]Object)}
+}
+
+func (s *Set) Type() ObjectType {
+	if s.Frozen {
+		return FROZENSET_OBJ
+	}
+	return SET_OBJ
+}
+
+func (s *Set) Inspect() string {
+	elems := []string{}
+	for _, e := range s.OrderedElements() {
+		elems = append(elems, e.Inspect())
+	}
+	inner := strings.Join(elems, ", ")
+	switch {
+	case s.Frozen && len(elems) == 0:
+		return "frozenset()"
+	case s.Frozen:
+		return "frozenset({" + inner + "})"
+	case len(elems) == 0:
+		return "set()"
+	}
+	return "{" + inner + "}"
+}
+
+// Add stores obj under key unless an equal element is already present.
+func (s *Set) Add(key HashKey, obj Object) {
+	if _, ok := s.Elements[key]; ok {
+		return
+	}
+	s.Elements[key] = obj
+	s.Keys = append(s.Keys, key)
+}
+
+// Remove deletes the element stored under key, reporting whether it was
+// present.
+func (s *Set) Remove(key HashKey) bool {
+	if _, ok := s.Elements[key]; !ok {
+		return false
+	}
+	delete(s.Elements, key)
+	for i, k := range s.Keys {
+		if k == key {
+			s.Keys = append(s.Keys[:i], s.Keys[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+func (s *Set) Contains(key HashKey) bool {
+	_, ok := s.Elements[key]
+	return ok
+}
+
+// OrderedElements returns the elements of the set in insertion order.
+func (s *Set) OrderedElements() []Object {
+	elements := make([]Object, 0, len(s.Keys))
+	for _, key := range s.Keys {
+		elements = append(elements, s.Elements[key])
+	}
+	return elements
+}
+
+// Iter yields the elements of the set in insertion order.
+func (s *Set) Iter() Iterator {
+	return &sliceIterator{elements: s.OrderedElements()}
+}
+
+// HashKey does not depend on insertion order, so equal sets share a key.
+// Only frozen sets can be used as keys; check with HashKeyOf.
+func (s *Set) HashKey() HashKey {
+	parts := make([]string, 0, len(s.Keys))
+	for _, key := range s.Keys {
+		parts = append(parts, key.encode())
+	}
+	sort.Strings(parts)
+	text := strings.Join(parts, "")
+	h := fnv.New64a()
+	h.Write([]byte(text))
+	return HashKey{Type: FROZENSET_OBJ, Value: h.Sum64(), Text: text}
+}
@@ -0,0 +1,72 @@
+package object
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// Stream is a named standard stream: stdin, which is read line by line, or
+// stdout and stderr, which can be passed to print as `file=`.
+type Stream struct {
+	Name   string
+	Reader *bufio.Reader // nil unless the stream is readable
+	Writer io.Writer     // nil unless the stream is writable
+}
+
+func (s *Stream) Type() ObjectType { return STREAM_OBJ }
+func (s *Stream) Inspect() string  { return "<" + s.Name + ">" }
+
+func (s *Stream) Write(p []byte) (int, error) {
+	if s.Writer == nil {
+		return 0, fmt.Errorf("stream %s is not writable", s.Name)
+	}
+	return s.Writer.Write(p)
+}
+
+// ReadLine returns the next line including its newline. A last line
+// without a newline is returned as is; after it ReadLine fails with io.EOF.
+func (s *Stream) ReadLine() (string, error) {
+	if s.Reader == nil {
+		return "", fmt.Errorf("stream %s is not readable", s.Name)
+	}
+	line, err := s.Reader.ReadString('\n')
+	if err == io.EOF && line != "" {
+		return line, nil
+	}
+	return line, err
+}
+
+// ReadAll returns everything left in the stream.
+func (s *Stream) ReadAll() (string, error) {
+	if s.Reader == nil {
+		return "", fmt.Errorf("stream %s is not readable", s.Name)
+	}
+	data, err := io.ReadAll(s.Reader)
+	return string(data), err
+}
+
+// lineIterator yields the lines of a stream or file until it is exhausted.
+type lineIterator struct {
+	source interface{ ReadLine() (string, error) }
+	done   bool
+}
+
+func (li *lineIterator) Next() (Object, bool) {
+	if li.done {
+		return nil, false
+	}
+	line, err := li.source.ReadLine()
+	if err == io.EOF {
+		li.done = true
+		return nil, false
+	}
+	if err != nil {
+		li.done = true
+		return &Error{Message: err.Error()}, true
+	}
+	return &String{Value: line}, true
+}
+
+// Iter yields the remaining lines of the stream, each with its newline.
+func (s *Stream) Iter() Iterator { return &lineIterator{source: s} }
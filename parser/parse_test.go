@@ -258,11 +258,11 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 		},
 		{
 			"3 > 5 == false",
-			"((3 > 5) == false)",
+			"(3 > 5 == false)",
 		},
 		{
 			"3 < 5 == true",
-			"((3 < 5) == true)",
+			"(3 < 5 == true)",
 		},
 		{
 			"1 + (2 + 3) + 4",
@@ -349,6 +349,114 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"add(a * b[2], b[1], 2 * [1, 2][1])",
 			"add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))",
 		},
+		{
+			"x |> parse |> filter(pred) |> sort",
+			"(((x |> parse) |> filter(pred)) |> sort)",
+		},
+		{
+			"a + b |> f(c * d)",
+			"((a + b) |> f((c * d)))",
+		},
+		{
+			"a or b |> f",
+			"((a or b) |> f)",
+		},
+		{
+			"2 ** 3 ** 2",
+			"(2 ** (3 ** 2))",
+		},
+		{
+			"-2 ** 2",
+			"(-(2 ** 2))",
+		},
+		{
+			"a * b ** c // d",
+			"((a * (b ** c)) // d)",
+		},
+		{
+			"a | b ^ c & d",
+			"(a | (b ^ (c & d)))",
+		},
+		{
+			"a + 1 in b",
+			"((a + 1) in b)",
+		},
+		{
+			"a not in b and c",
+			"((a not in b) and c)",
+		},
+		{
+			"a is not none or b is c",
+			"((a is not none) or (b is c))",
+		},
+		{
+			"0 <= i < n",
+			"(0 <= i < n)",
+		},
+		{
+			"a < b in c is not d",
+			"(a < b in c is not d)",
+		},
+		{
+			"a == b != c",
+			"(a == b != c)",
+		},
+		{
+			"a < b == c != d >= e",
+			"(a < b == c != d >= e)",
+		},
+		{
+			"(a < b) == c",
+			"((a < b) == c)",
+		},
+		{
+			"3 > 5 == false",
+			"(3 > 5 == false)",
+		},
+		{
+			"a if b else c",
+			"(a if b else c)",
+		},
+		{
+			"a + 1 if b or c else d * 2",
+			"((a + 1) if (b or c) else (d * 2))",
+		},
+		{
+			"a if b else c if d else e",
+			"(a if b else (c if d else e))",
+		},
+		{
+			"f(a if b else c)",
+			"f((a if b else c))",
+		},
+		{
+			"a & b == c",
+			"((a & b) == c)",
+		},
+		{
+			"1 << 2 + 3 >> 1",
+			"((1 << (2 + 3)) >> 1)",
+		},
+		{
+			"~a & b",
+			"((~a) & b)",
+		},
+		{
+			"-a.b * c",
+			"((-a.b) * c)",
+		},
+		{
+			"a.b(c).d[1]",
+			"(a.b(c).d[1])",
+		},
+		{
+			"x |> s.join",
+			"(x |> s.join)",
+		},
+		{
+			"f(a, b=c + 1)",
+			"f(a, b=(c + 1))",
+		},
 	}
 
 	for i, tt := range tests {
@@ -700,6 +808,74 @@ func TestParsingHashLiteralsStringKeys(t *testing.T) {
 		expectedValue := expected[literal.String()]
 		testIntegerLiteral(t, value, expectedValue)
 	}
+	for i, want := range []string{"one", "two", "three"} {
+		if hash.Keys[i].String() != want {
+			t.Errorf("hash.Keys[%d] wrong. want=%q, got=%q", i, want, hash.Keys[i].String())
+		}
+	}
+}
+
+func TestPositionalArgumentAfterKeyword(t *testing.T) {
+	l := lexer.New("f(a=1, 2)")
+	p := New(l)
+	p.ParseProgram()
+	errors := p.Errors()
+	if len(errors) != 1 || errors[0] != "positional argument follows keyword argument" {
+		t.Errorf("wrong parser errors. got=%q", errors)
+	}
+}
+
+func TestParsingSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a[1:2]", "(a[1:2])"},
+		{"a[:2]", "(a[:2])"},
+		{"a[1:]", "(a[1:])"},
+		{"a[:]", "(a[:])"},
+		{"a[::-1]", "(a[::(-1)])"},
+		{"a[i + 1:n:2]", "(a[(i + 1):n:2])"},
+	}
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		slice, ok := stmt.Expression.(*ast.SliceExpression)
+		if !ok {
+			t.Fatalf("exp is not ast.SliceExpression. got=%T", stmt.Expression)
+		}
+		if slice.String() != tt.expected {
+			t.Errorf("slice.String() wrong. expected=%q, got=%q", tt.expected, slice.String())
+		}
+	}
+}
+
+func TestParsingSetLiteral(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"{1, 2 + 3, a}", "{1, (2 + 3), a}"},
+		{"{1}", "{1}"},
+		{"{1, 2,}", "{1, 2}"},
+	}
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		set, ok := stmt.Expression.(*ast.SetLiteral)
+		if !ok {
+			t.Fatalf("exp is not ast.SetLiteral. got=%T", stmt.Expression)
+		}
+		if set.String() != tt.expected {
+			t.Errorf("set.String() wrong. expected=%q, got=%q", tt.expected, set.String())
+		}
+	}
 }
 
 func TestParsingEmptyHashLiteral(t *testing.T) {
@@ -757,3 +933,207 @@ func TestParsingHashLiteralsWithExpressions(t *testing.T) {
 		testFunc(value)
 	}
 }
+
+func TestParsingMatchStatement(t *testing.T) {
+	input := `
+match point:
+    case (0, 0):
+        return "origin"
+    case [x, *rest] if x > 0: return x
+    case {"name": name}:
+        return name
+    case int(n):
+        return n
+    case _:
+        return -1
+`
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+	stmt, ok := program.Statements[0].(*ast.MatchStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.MatchStatement. got=%T", program.Statements[0])
+	}
+	if !testIdentifier(t, stmt.Subject, "point") {
+		return
+	}
+
+	expected := []struct {
+		pattern string
+		guard   string
+	}{
+		{"(0, 0)", ""},
+		{"[x, *rest]", "(x > 0)"},
+		{`{name:name}`, ""},
+		{"int(n)", ""},
+		{"_", ""},
+	}
+	if len(stmt.Cases) != len(expected) {
+		t.Fatalf("match has wrong number of cases. got=%d", len(stmt.Cases))
+	}
+	for i, tt := range expected {
+		clause := stmt.Cases[i]
+		if clause.Pattern.String() != tt.pattern {
+			t.Errorf("cases[%d] pattern wrong. expected=%q, got=%q", i, tt.pattern, clause.Pattern.String())
+		}
+		guard := ""
+		if clause.Guard != nil {
+			guard = clause.Guard.String()
+		}
+		if guard != tt.guard {
+			t.Errorf("cases[%d] guard wrong. expected=%q, got=%q", i, tt.guard, guard)
+		}
+		if len(clause.Body.Statements) != 1 {
+			t.Errorf("cases[%d] body does not contain 1 statement. got=%d", i, len(clause.Body.Statements))
+		}
+	}
+}
+
+func TestParsingYieldStatement(t *testing.T) {
+	input := `
+spell gen(x):
+    yield x + 1
+    yield
+`
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	fn, ok := program.Statements[0].(*ast.FunctionDefinition)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.FunctionDefinition. got=%T", program.Statements[0])
+	}
+	if len(fn.Body.Statements) != 2 {
+		t.Fatalf("Function body does not contain 2 statements. got=%d", len(fn.Body.Statements))
+	}
+	first, ok := fn.Body.Statements[0].(*ast.YieldStatement)
+	if !ok {
+		t.Fatalf("body statement is not ast.YieldStatement. got=%T", fn.Body.Statements[0])
+	}
+	testInfixExpression(t, first.Value, "x", "+", 1)
+	bare, ok := fn.Body.Statements[1].(*ast.YieldStatement)
+	if !ok {
+		t.Fatalf("body statement is not ast.YieldStatement. got=%T", fn.Body.Statements[1])
+	}
+	if bare.Value != nil {
+		t.Errorf("bare yield has a value. got=%s", bare.Value.String())
+	}
+}
+
+func TestParsingDecorators(t *testing.T) {
+	input := `
+@memoize
+@timed(3)
+spell add(x, y):
+    return x + y
+`
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+	stmt, ok := program.Statements[0].(*ast.FunctionDefinition)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.FunctionDefinition. got=%T", program.Statements[0])
+	}
+	if len(stmt.Decorators) != 2 {
+		t.Fatalf("wrong number of decorators. got=%d", len(stmt.Decorators))
+	}
+	testIdentifier(t, stmt.Decorators[0], "memoize")
+	if stmt.Decorators[1].String() != "timed(3)" {
+		t.Errorf("decorator wrong. expected=%q, got=%q", "timed(3)", stmt.Decorators[1].String())
+	}
+}
+
+func TestParsingDecoratorWithoutDefinition(t *testing.T) {
+	input := `
+@memoize
+x = 5
+`
+	l := lexer.New(input)
+	p := New(l)
+	p.ParseProgram()
+
+	if len(p.Errors()) == 0 {
+		t.Fatalf("expected parser errors, but got none")
+	}
+}
+
+func TestParsingSimpleStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"assert x > 1", "assert (x > 1)"},
+		{`assert x, "need x"`, "assert x, need x"},
+		{"ignore", "ignore"},
+		{"var x = 1 + 2", "var x = (1 + 2)"},
+		{"var x", "var x"},
+		{"const LIMIT = 10", "const LIMIT = 10"},
+		{"global a, b", "global a, b"},
+		{"nonlocal n", "nonlocal n"},
+		{"with open(p) as f: read(f)", "with open(p) as f:\nread(f)\n"},
+		{"with lock:\n    x = 1", "with lock:\nx = 1\n"},
+		{"spell f(): ignore", "spell f():\nignore\n"},
+		{"import text", "import text"},
+		{"import itertools as it", "import itertools as it"},
+	}
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		if len(program.Statements) != 1 {
+			t.Fatalf("program has %d statements, want 1", len(program.Statements))
+		}
+		if got := program.Statements[0].String(); got != tt.expected {
+			t.Errorf("wrong String(). expected=%q, got=%q", tt.expected, got)
+		}
+	}
+}
+
+func TestCompoundAssignmentStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		operator string
+	}{
+		{"x = 1", "="},
+		{"x += 1", "+="},
+		{"x -= 1", "-="},
+		{"x *= 1", "*="},
+		{"x /= 1", "/="},
+		{"x **= 1", "**="},
+		{"x //= 1", "//="},
+		{"x &= 1", "&="},
+		{"x |= 1", "|="},
+		{"x ^= 1", "^="},
+		{"x <<= 1", "<<="},
+		{"x >>= 1", ">>="},
+	}
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.AssignStatement)
+		if !ok {
+			t.Fatalf("%s: statement is not ast.AssignStatement. got=%T", tt.input, program.Statements[0])
+		}
+		if stmt.Operator != tt.operator {
+			t.Errorf("%s: operator wrong. expected=%q, got=%q", tt.input, tt.operator, stmt.Operator)
+		}
+		if stmt.String() != tt.input {
+			t.Errorf("stmt.String() wrong. expected=%q, got=%q", tt.input, stmt.String())
+		}
+	}
+}
@@ -13,13 +13,19 @@ const (
 	_           int = iota
 	LOWEST      int = iota
 	ASSIGN          // =
+	TERNARY         // X if Y else Z
+	PIPELINE        // |>
 	LOGICAL_OR      // or
 	LOGICAL_AND     // and
-	EQUALS          // ==, !=
-	LESSGREATER     // >, <
+	COMPARISON      // ==, !=, >, <, >=, <=, in, not in, is, is not
+	BIT_OR          // |
+	BIT_XOR         // ^
+	BIT_AND         // &
+	SHIFT           // <<, >>
 	SUM             // +, -
-	PRODUCT         // *, /, %
-	PREFIX          // -X, !X, ++X, --X
+	PRODUCT         // *, /, //, %
+	PREFIX          // -X, !X, ~X, ++X, --X
+	POWER           // X ** Y
 	CALL            // myFunction(X)
 	POSTFIX         // X++, X--
 	INDEX
@@ -31,23 +37,71 @@ var precedences = map[token.TokenType]int{
 	token.DECREMENT:       ASSIGN, // -=
 	token.MULTASSGN:       ASSIGN, // *=
 	token.DIVASSGN:        ASSIGN, // /=
-	token.EQ:              EQUALS,
-	token.NOT_EQ:          EQUALS,
-	token.LT:              LESSGREATER,
-	token.GT:              LESSGREATER,
-	token.LE:              LESSGREATER,
-	token.GE:              LESSGREATER,
+	token.EQ:              COMPARISON,
+	token.NOT_EQ:          COMPARISON,
+	token.LT:              COMPARISON,
+	token.GT:              COMPARISON,
+	token.LE:              COMPARISON,
+	token.GE:              COMPARISON,
+	token.IN:              COMPARISON,
+	token.NOT:             COMPARISON, // only as `not in`
+	token.IS:              COMPARISON,
 	token.PLUS:            SUM,
 	token.MINUS:           SUM,
 	token.SLASH:           PRODUCT,
 	token.ASTERISK:        PRODUCT,
 	token.MOD:             PRODUCT,
+	token.FLOOR_DIV:       PRODUCT,
+	token.POWER:           POWER,
+	token.PIPE:            BIT_OR,
+	token.CARET:           BIT_XOR,
+	token.AMPERSAND:       BIT_AND,
+	token.LSHIFT:          SHIFT,
+	token.RSHIFT:          SHIFT,
 	token.PLUS_INCREMENT:  POSTFIX,
 	token.MINUS_DECREMENT: POSTFIX,
 	token.LPAREN:          CALL,
 	token.LBRACK:          INDEX,
+	token.DOT:             INDEX,
 	token.OR:              LOGICAL_OR,
 	token.AND:             LOGICAL_AND,
+	token.PIPELINE:        PIPELINE,
+	token.IF:              TERNARY,
+}
+
+// comparisonOperators all share one precedence and chain with each other:
+// `a < b <= c` means `a < b and b <= c`.
+var comparisonOperators = map[token.TokenType]bool{
+	token.EQ:     true,
+	token.NOT_EQ: true,
+	token.LT:     true,
+	token.GT:     true,
+	token.LE:     true,
+	token.GE:     true,
+	token.IN:     true,
+	token.NOT:    true,
+	token.IS:     true,
+}
+
+// assignmentOperators are the tokens that may follow a name in an assignment statement.
+var assignmentOperators = map[token.TokenType]bool{
+	token.ASSIGN:        true,
+	token.INCREMENT:     true,
+	token.DECREMENT:     true,
+	token.MULTASSGN:     true,
+	token.DIVASSGN:      true,
+	token.POWASSGN:      true,
+	token.FLOORDIVASSGN: true,
+	token.ANDASSGN:      true,
+	token.ORASSGN:       true,
+	token.XORASSGN:      true,
+	token.LSHIFTASSGN:   true,
+	token.RSHIFTASSGN:   true,
+}
+
+// rightAssociative operators group from the right: 2 ** 3 ** 2 is 2 ** (3 ** 2).
+var rightAssociative = map[token.TokenType]bool{
+	token.POWER: true,
 }
 
 type (
@@ -80,15 +134,18 @@ func New(l *lexer.Lexer) *Parser {
 
 	// Register prefix parsers
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
+	p.registerPrefix(token.RANGE, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
 	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
+	p.registerPrefix(token.TILDE, p.parsePrefixExpression)
 	p.registerPrefix(token.PLUS_INCREMENT, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS_DECREMENT, p.parsePrefixExpression)
 	// p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
 	p.registerPrefix(token.TRUE, p.parseBoolean)
 	p.registerPrefix(token.FALSE, p.parseBoolean)
+	p.registerPrefix(token.NONE, p.parseNone)
 	p.registerPrefix(token.COLON, func() ast.Expression {
 		return nil
 	})
@@ -108,12 +165,19 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.SLASH, p.parseInfixExpression)
 	p.registerInfix(token.ASTERISK, p.parseInfixExpression)
 	p.registerInfix(token.MOD, p.parseInfixExpression)
-	p.registerInfix(token.EQ, p.parseInfixExpression)
-	p.registerInfix(token.NOT_EQ, p.parseInfixExpression)
-	p.registerInfix(token.LT, p.parseInfixExpression)
-	p.registerInfix(token.GT, p.parseInfixExpression)
-	p.registerInfix(token.LE, p.parseInfixExpression)
-	p.registerInfix(token.GE, p.parseInfixExpression)
+	p.registerInfix(token.FLOOR_DIV, p.parseInfixExpression)
+	p.registerInfix(token.POWER, p.parseInfixExpression)
+	p.registerInfix(token.PIPE, p.parseInfixExpression)
+	p.registerInfix(token.CARET, p.parseInfixExpression)
+	p.registerInfix(token.AMPERSAND, p.parseInfixExpression)
+	p.registerInfix(token.LSHIFT, p.parseInfixExpression)
+	p.registerInfix(token.RSHIFT, p.parseInfixExpression)
+	p.registerInfix(token.EQ, p.parseComparisonExpression)
+	p.registerInfix(token.NOT_EQ, p.parseComparisonExpression)
+	p.registerInfix(token.LT, p.parseComparisonExpression)
+	p.registerInfix(token.GT, p.parseComparisonExpression)
+	p.registerInfix(token.LE, p.parseComparisonExpression)
+	p.registerInfix(token.GE, p.parseComparisonExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
 	p.registerInfix(token.OR, p.parseInfixExpression)
 	p.registerInfix(token.AND, p.parseInfixExpression)
@@ -122,14 +186,31 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.MULTASSGN, p.parseInfixExpression)
 	p.registerInfix(token.DIVASSGN, p.parseInfixExpression)
 	p.registerInfix(token.LBRACK, p.parseIndexExpression)
+	p.registerInfix(token.DOT, p.parseMemberExpression)
+	p.registerInfix(token.IN, p.parseComparisonExpression)
+	p.registerInfix(token.NOT, p.parseComparisonExpression)
+	p.registerInfix(token.IS, p.parseComparisonExpression)
+	p.registerInfix(token.IF, p.parseConditionalExpression)
+	p.registerInfix(token.PIPELINE, p.parsePipelineExpression)
 	// Register postfix parsers
 	p.registerPostfix(token.PLUS_INCREMENT, p.parsePostfixExpression)
 	p.registerPostfix(token.MINUS_DECREMENT, p.parsePostfixExpression)
 	// Register statement parsers
 	p.registerStatement(token.RETURN, p.parseReturnStatement)
+	p.registerStatement(token.YIELD, p.parseYieldStatement)
 	p.registerStatement(token.IF, p.parseIfStatement)
 	p.registerStatement(token.FOR, p.parseForStatement)
 	p.registerStatement(token.SPELL, p.parseFunctionDefinition)
+	p.registerStatement(token.AT, p.parseDecoratedDefinition)
+	p.registerStatement(token.MATCH, p.parseMatchStatement)
+	p.registerStatement(token.IGNORE, p.parseIgnoreStatement)
+	p.registerStatement(token.WITH, p.parseWithStatement)
+	p.registerStatement(token.VAR, p.parseVarStatement)
+	p.registerStatement(token.CONST, p.parseVarStatement)
+	p.registerStatement(token.GLOBAL, p.parseScopeStatement)
+	p.registerStatement(token.NONLOCAL, p.parseScopeStatement)
+	p.registerStatement(token.ASSERT, p.parseAssertStatement)
+	p.registerStatement(token.IMPORT, p.parseImportStatement)
 
 	return p
 }
@@ -186,18 +267,25 @@ func (p *Parser) parseParenExpression() ast.Expression {
 	return firstExpr
 }
 
+// parseHashLiteral parses both hash literals and set literals, which are
+// told apart by whether the first element is followed by a colon. `{}` is
+// an empty hash.
 func (p *Parser) parseHashLiteral() ast.Expression {
 	hash := &ast.HashLiteral{Token: p.currToken}
 	hash.Pairs = make(map[ast.Expression]ast.Expression)
 	for !p.peekTokenIs(token.RBRACE) {
 		p.nextToken()
 		key := p.parseExpression(LOWEST)
+		if len(hash.Keys) == 0 && !p.peekTokenIs(token.COLON) {
+			return p.parseSetLiteral(hash.Token, key)
+		}
 		if !p.expectPeek(token.COLON) {
 			return nil
 		}
 		p.nextToken()
 		value := p.parseExpression(LOWEST)
 		hash.Pairs[key] = value
+		hash.Keys = append(hash.Keys, key)
 		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
 			return nil
 		}
@@ -227,15 +315,56 @@ func (p *Parser) parseFloatLiteral() ast.Expression {
 }
 
 func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
-	exp := &ast.IndexExpression{Token: p.currToken, Left: left}
+	tok := p.currToken
 	p.nextToken()
-	exp.Index = p.parseExpression(LOWEST)
+	if p.currTokenIs(token.COLON) {
+		return p.parseSliceExpression(tok, left, nil)
+	}
+	index := p.parseExpression(LOWEST)
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		return p.parseSliceExpression(tok, left, index)
+	}
+	if !p.expectPeek(token.RBRACK) {
+		return nil
+	}
+	return &ast.IndexExpression{Token: tok, Left: left, Index: index}
+}
+
+func (p *Parser) parseMemberExpression(object ast.Expression) ast.Expression {
+	exp := &ast.MemberExpression{Token: p.currToken, Object: object}
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	exp.Member = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+	return exp
+}
+
+// parseSliceExpression parses the rest of `left[start:stop:step]` with the
+// current token on the first colon.
+func (p *Parser) parseSliceExpression(tok token.Token, left, start ast.Expression) ast.Expression {
+	exp := &ast.SliceExpression{Token: tok, Left: left, Start: start}
+	exp.Stop = p.parseSliceBound()
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		exp.Step = p.parseSliceBound()
+	}
 	if !p.expectPeek(token.RBRACK) {
 		return nil
 	}
 	return exp
 }
 
+// parseSliceBound parses an optional slice bound following the current
+// colon, returning nil when it is omitted.
+func (p *Parser) parseSliceBound() ast.Expression {
+	if p.peekTokenIs(token.COLON) || p.peekTokenIs(token.RBRACK) {
+		return nil
+	}
+	p.nextToken()
+	return p.parseExpression(LOWEST)
+}
+
 func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 	list := []ast.Expression{}
 	if p.peekTokenIs(end) {
@@ -285,6 +414,28 @@ func (p *Parser) parseStringLiteral() ast.Expression {
 	return &ast.StringLiteral{Token: p.currToken, Value: p.currToken.Literal}
 }
 
+// parseSetLiteral parses the rest of a set literal whose first element has
+// already been parsed.
+func (p *Parser) parseSetLiteral(tok token.Token, first ast.Expression) ast.Expression {
+	set := &ast.SetLiteral{Token: tok, Elements: []ast.Expression{first}}
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		if p.peekTokenIs(token.RBRACE) {
+			break
+		}
+		p.nextToken()
+		set.Elements = append(set.Elements, p.parseExpression(LOWEST))
+	}
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+	return set
+}
+
+func (p *Parser) parseNone() ast.Expression {
+	return &ast.NoneLiteral{Token: p.currToken}
+}
+
 func (p *Parser) parseBoolean() ast.Expression {
 	value := (p.currToken.Type == token.TRUE)
 	return &ast.Boolean{Token: p.currToken, Value: value}
@@ -314,6 +465,9 @@ func (p *Parser) ParseProgram() *ast.Program {
 				break
 			}
 		}
+		if p.currToken.Type == token.EOF {
+			break
+		}
 
 		stmt := p.parseStatement()
 		if stmt != nil {
@@ -336,8 +490,8 @@ func (p *Parser) parseStatement() ast.Statement {
 			return fn()
 		}
 	}
-	// Handle assignment statements
-	if p.currToken.Type == token.IDENT && p.peekTokenIs(token.ASSIGN) {
+	// Handle assignment statements, including compound ones like x += 1
+	if p.currToken.Type == token.IDENT && assignmentOperators[p.peekToken.Type] {
 		return p.parseAssignmentStatement()
 	}
 
@@ -352,13 +506,11 @@ func (p *Parser) parseAssignmentStatement() *ast.AssignStatement {
 		Value: p.currToken.Literal,
 	}
 
-	if !p.expectPeek(token.ASSIGN) &&
-		!p.expectPeek(token.INCREMENT) &&
-		!p.expectPeek(token.DECREMENT) &&
-		!p.expectPeek(token.MULTASSGN) &&
-		!p.expectPeek(token.DIVASSGN) {
+	if !assignmentOperators[p.peekToken.Type] {
+		p.peekError(token.ASSIGN)
 		return nil
 	}
+	p.nextToken()
 
 	stmt.Operator = p.currToken.Literal
 
@@ -383,6 +535,161 @@ func (p *Parser) parseReturnStatement() ast.Statement {
 	return stmt
 }
 
+func (p *Parser) parseYieldStatement() ast.Statement {
+	stmt := &ast.YieldStatement{Token: p.currToken}
+
+	// A bare `yield` produces none
+	if p.peekTokenIs(token.NEWLINE) || p.peekTokenIs(token.DEDENT) || p.peekTokenIs(token.EOF) {
+		return stmt
+	}
+
+	p.nextToken()
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.NEWLINE) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+func (p *Parser) parseVarStatement() ast.Statement {
+	stmt := &ast.VarStatement{Token: p.currToken}
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	stmt.Name = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+
+	if p.peekTokenIs(token.ASSIGN) {
+		p.nextToken()
+		p.nextToken()
+		stmt.Value = p.parseExpression(LOWEST)
+		if stmt.Value == nil {
+			return nil
+		}
+	} else if stmt.Token.Type == token.CONST {
+		p.peekError(token.ASSIGN)
+		return nil
+	}
+
+	if p.peekTokenIs(token.NEWLINE) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+func (p *Parser) parseScopeStatement() ast.Statement {
+	stmt := &ast.ScopeStatement{Token: p.currToken}
+	for {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Names = append(stmt.Names, &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal})
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if p.peekTokenIs(token.NEWLINE) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+func (p *Parser) parseImportStatement() ast.Statement {
+	stmt := &ast.ImportStatement{Token: p.currToken}
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	stmt.Name = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+
+	if p.peekTokenIs(token.AS) {
+		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Alias = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+	}
+
+	if p.peekTokenIs(token.NEWLINE) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+func (p *Parser) parseWithStatement() ast.Statement {
+	stmt := &ast.WithStatement{Token: p.currToken}
+
+	p.nextToken()
+	stmt.Context = p.parseExpression(LOWEST)
+	if stmt.Context == nil {
+		return nil
+	}
+
+	if p.peekTokenIs(token.AS) {
+		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Name = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+	}
+
+	if !p.expectPeek(token.COLON) {
+		return nil
+	}
+
+	if p.peekTokenIs(token.NEWLINE) {
+		// MULTI-LINE
+		p.nextToken()
+		if !p.expectPeek(token.INDENT) {
+			return nil
+		}
+		stmt.Body = p.parseBlockStatement()
+	} else {
+		// SINGLE-LINE
+		p.nextToken()
+		singleStmt := p.parseStatement()
+		stmt.Body = &ast.BlockStatement{
+			Token:      p.currToken,
+			Statements: []ast.Statement{singleStmt},
+		}
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseIgnoreStatement() ast.Statement {
+	stmt := &ast.IgnoreStatement{Token: p.currToken}
+	if p.peekTokenIs(token.NEWLINE) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+func (p *Parser) parseAssertStatement() ast.Statement {
+	stmt := &ast.AssertStatement{Token: p.currToken}
+
+	p.nextToken()
+	stmt.Condition = p.parseExpression(LOWEST)
+	if stmt.Condition == nil {
+		return nil
+	}
+
+	if p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		p.nextToken()
+		stmt.Message = p.parseExpression(LOWEST)
+		if stmt.Message == nil {
+			return nil
+		}
+	}
+
+	if p.peekTokenIs(token.NEWLINE) {
+		p.nextToken()
+	}
+	return stmt
+}
+
 func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 	stmt := &ast.ExpressionStatement{Token: p.currToken}
 
@@ -429,6 +736,12 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 		!p.peekTokenIs(token.EOF) &&
 		precedence < p.peekPrecedence() {
 
+		if postfix := p.postfixParseFns[p.peekToken.Type]; postfix != nil {
+			p.nextToken()
+			leftExp = postfix(leftExp)
+			continue
+		}
+
 		infix := p.infixParseFns[p.peekToken.Type]
 		if infix == nil {
 			return leftExp
@@ -501,6 +814,9 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	}
 
 	precedence := p.currPrecedence()
+	if rightAssociative[p.currToken.Type] {
+		precedence--
+	}
 	p.nextToken()
 	expression.Right = p.parseExpression(precedence)
 	if expression.Right == nil {
@@ -511,6 +827,91 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	return expression
 }
 
+// parseComparisonExpression parses a comparison together with any further
+// comparisons of the same precedence chained after it. A lone comparison is
+// an InfixExpression; `0 <= i < n` becomes a single ChainedComparison.
+func (p *Parser) parseComparisonExpression(left ast.Expression) ast.Expression {
+	tok := p.currToken
+	precedence := p.currPrecedence()
+	operator, ok := p.parseComparisonOperator()
+	if !ok {
+		return nil
+	}
+	p.nextToken()
+	right := p.parseExpression(precedence)
+	if right == nil {
+		msg := fmt.Sprintf("no right-hand expression for infix operator %q", operator)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+	if !p.peekIsChainedComparison(precedence) {
+		return &ast.InfixExpression{Token: tok, Operator: operator, Left: left, Right: right}
+	}
+
+	chain := &ast.ChainedComparison{
+		Token:     tok,
+		Operands:  []ast.Expression{left, right},
+		Operators: []string{operator},
+	}
+	for p.peekIsChainedComparison(precedence) {
+		p.nextToken()
+		operator, ok := p.parseComparisonOperator()
+		if !ok {
+			return nil
+		}
+		p.nextToken()
+		right := p.parseExpression(precedence)
+		if right == nil {
+			msg := fmt.Sprintf("no right-hand expression for infix operator %q", operator)
+			p.errors = append(p.errors, msg)
+			return nil
+		}
+		chain.Operators = append(chain.Operators, operator)
+		chain.Operands = append(chain.Operands, right)
+	}
+	return chain
+}
+
+// parseComparisonOperator reads the comparison operator at the current
+// token, consuming the second word of `not in` and `is not`.
+func (p *Parser) parseComparisonOperator() (string, bool) {
+	switch p.currToken.Type {
+	case token.NOT:
+		if !p.expectPeek(token.IN) {
+			return "", false
+		}
+		return "not in", true
+	case token.IS:
+		if p.peekTokenIs(token.NOT) {
+			p.nextToken()
+			return "is not", true
+		}
+	}
+	return p.currToken.Literal, true
+}
+
+func (p *Parser) peekIsChainedComparison(precedence int) bool {
+	return comparisonOperators[p.peekToken.Type] && p.peekPrecedence() == precedence
+}
+
+// parseConditionalExpression parses `consequence if condition else alternative`.
+func (p *Parser) parseConditionalExpression(consequence ast.Expression) ast.Expression {
+	expression := &ast.ConditionalExpression{Token: p.currToken, Consequence: consequence}
+
+	precedence := p.currPrecedence()
+	p.nextToken()
+	expression.Condition = p.parseExpression(precedence)
+	if !p.expectPeek(token.ELSE) {
+		return nil
+	}
+	p.nextToken()
+	expression.Alternative = p.parseExpression(precedence - 1)
+	if expression.Condition == nil || expression.Alternative == nil {
+		return nil
+	}
+	return expression
+}
+
 func (p *Parser) parsePostfixExpression(left ast.Expression) ast.Expression {
 	expression := &ast.PostfixExpression{
 		Token:    p.currToken,
@@ -670,6 +1071,10 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	}
 
 	for !p.currTokenIs(token.DEDENT) && !p.currTokenIs(token.EOF) {
+		if p.currTokenIs(token.NEWLINE) {
+			p.nextToken()
+			continue
+		}
 		stmt := p.parseStatement()
 		if stmt != nil {
 			block.Statements = append(block.Statements, stmt)
@@ -698,6 +1103,19 @@ func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 	return exp
 }
 
+func (p *Parser) parsePipelineExpression(left ast.Expression) ast.Expression {
+	exp := &ast.PipelineExpression{Token: p.currToken, Left: left}
+
+	precedence := p.currPrecedence()
+	p.nextToken()
+	exp.Right = p.parseExpression(precedence)
+	if exp.Right == nil {
+		p.errors = append(p.errors, "no spell on the right-hand side of |>")
+		return nil
+	}
+	return exp
+}
+
 func (p *Parser) parseCallArguments() []ast.Expression {
 	args := []ast.Expression{}
 
@@ -707,12 +1125,17 @@ func (p *Parser) parseCallArguments() []ast.Expression {
 	}
 
 	p.nextToken()
-	args = append(args, p.parseExpression(LOWEST))
+	args = append(args, p.parseCallArgument())
 
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
 		p.nextToken()
-		args = append(args, p.parseExpression(LOWEST))
+		arg := p.parseCallArgument()
+		_, isKeyword := arg.(*ast.KeywordArgument)
+		if _, afterKeyword := args[len(args)-1].(*ast.KeywordArgument); afterKeyword && !isKeyword {
+			p.errors = append(p.errors, "positional argument follows keyword argument")
+		}
+		args = append(args, arg)
 	}
 
 	if !p.expectPeek(token.RPAREN) {
@@ -722,6 +1145,20 @@ func (p *Parser) parseCallArguments() []ast.Expression {
 	return args
 }
 
+// parseCallArgument parses one argument of a call, which is either an
+// expression or a `name=value` keyword argument.
+func (p *Parser) parseCallArgument() ast.Expression {
+	if !p.currTokenIs(token.IDENT) || !p.peekTokenIs(token.ASSIGN) {
+		return p.parseExpression(LOWEST)
+	}
+	arg := &ast.KeywordArgument{Token: p.currToken}
+	arg.Name = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+	p.nextToken()
+	p.nextToken()
+	arg.Value = p.parseExpression(LOWEST)
+	return arg
+}
+
 func (p *Parser) parseForStatement() ast.Statement {
 	stmt := &ast.ForStatement{Token: p.currToken}
 	if !p.expectPeek(token.IDENT) {
@@ -815,6 +1252,37 @@ func (p *Parser) parseFunctionDefinition() ast.Statement {
 	return stmt
 }
 
+func (p *Parser) parseDecoratedDefinition() ast.Statement {
+	decorators := []ast.Expression{}
+
+	// One `@expr` per line, directly above the definition
+	for p.currTokenIs(token.AT) {
+		p.nextToken()
+		decorator := p.parseExpression(LOWEST)
+		if decorator == nil {
+			return nil
+		}
+		decorators = append(decorators, decorator)
+		if !p.expectPeek(token.NEWLINE) {
+			return nil
+		}
+		p.nextToken()
+	}
+
+	if !p.currTokenIs(token.SPELL) {
+		msg := fmt.Sprintf("expected spell definition after decorator, got %s instead", p.currToken.Type)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+
+	stmt, ok := p.parseFunctionDefinition().(*ast.FunctionDefinition)
+	if !ok {
+		return nil
+	}
+	stmt.Decorators = decorators
+	return stmt
+}
+
 func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 	identifiers := []*ast.Identifier{}
 
@@ -847,3 +1315,289 @@ func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 
 	return identifiers
 }
+
+func (p *Parser) parseMatchStatement() ast.Statement {
+	stmt := &ast.MatchStatement{Token: p.currToken}
+
+	p.nextToken()
+	stmt.Subject = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.COLON) {
+		return nil
+	}
+	if !p.expectPeek(token.NEWLINE) {
+		return nil
+	}
+	if !p.expectPeek(token.INDENT) {
+		return nil
+	}
+	p.nextToken()
+
+	// Each arm is a `case` line; the match ends at the DEDENT closing the arms
+	for !p.currTokenIs(token.DEDENT) && !p.currTokenIs(token.EOF) {
+		if p.currTokenIs(token.NEWLINE) {
+			p.nextToken()
+			continue
+		}
+		if !p.currTokenIs(token.CASE) {
+			msg := fmt.Sprintf("expected case in match body, got %s instead", p.currToken.Type)
+			p.errors = append(p.errors, msg)
+			return nil
+		}
+		clause := p.parseCaseClause()
+		if clause == nil {
+			return nil
+		}
+		stmt.Cases = append(stmt.Cases, clause)
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseCaseClause() *ast.CaseClause {
+	clause := &ast.CaseClause{Token: p.currToken}
+
+	p.nextToken()
+	clause.Pattern = p.parsePattern()
+	if clause.Pattern == nil {
+		return nil
+	}
+
+	// Optional guard => case x if x > 0:
+	if p.peekTokenIs(token.IF) {
+		p.nextToken()
+		p.nextToken()
+		clause.Guard = p.parseExpression(LOWEST)
+	}
+
+	if !p.expectPeek(token.COLON) {
+		return nil
+	}
+
+	if p.peekTokenIs(token.NEWLINE) {
+		// MULTI-LINE
+		p.nextToken()
+		if !p.expectPeek(token.INDENT) {
+			return nil
+		}
+		clause.Body = p.parseBlockStatement()
+	} else {
+		// SINGLE-LINE
+		p.nextToken()
+		singleStmt := p.parseStatement()
+		clause.Body = &ast.BlockStatement{
+			Token:      p.currToken,
+			Statements: []ast.Statement{singleStmt},
+		}
+	}
+
+	return clause
+}
+
+func (p *Parser) parsePattern() ast.Pattern {
+	switch p.currToken.Type {
+	case token.IDENT:
+		if p.currToken.Literal == "_" {
+			return &ast.WildcardPattern{Token: p.currToken}
+		}
+		ident := &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+		if p.peekTokenIs(token.LPAREN) {
+			p.nextToken()
+			return p.parseClassPattern(ident)
+		}
+		return &ast.CapturePattern{Token: p.currToken, Name: ident}
+	case token.MINUS:
+		if !p.peekTokenIs(token.INT) && !p.peekTokenIs(token.FLOAT) {
+			msg := fmt.Sprintf("expected number after '-' in case pattern, got %s instead", p.peekToken.Type)
+			p.errors = append(p.errors, msg)
+			return nil
+		}
+		fallthrough
+	case token.INT, token.FLOAT, token.STRING, token.TRUE, token.FALSE:
+		pattern := &ast.LiteralPattern{Token: p.currToken}
+		pattern.Value = p.prefixParseFns[p.currToken.Type]()
+		if pattern.Value == nil {
+			return nil
+		}
+		return pattern
+	case token.LBRACK:
+		pattern := &ast.SequencePattern{Token: p.currToken}
+		pattern.Elements = p.parseSequencePatternElements(token.RBRACK)
+		if pattern.Elements == nil {
+			return nil
+		}
+		return pattern
+	case token.LPAREN:
+		return p.parseParenPattern()
+	case token.LBRACE:
+		return p.parseHashPattern()
+	default:
+		msg := fmt.Sprintf("unexpected %s in case pattern", p.currToken.Type)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+}
+
+// parseSequencePatternElement parses one element of a sequence pattern, which
+// may be a `*rest` capture.
+func (p *Parser) parseSequencePatternElement() ast.Pattern {
+	if !p.currTokenIs(token.ASTERISK) {
+		return p.parsePattern()
+	}
+	pattern := &ast.StarPattern{Token: p.currToken}
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	if p.currToken.Literal != "_" {
+		pattern.Name = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+	}
+	return pattern
+}
+
+func (p *Parser) parseSequencePatternElements(end token.TokenType) []ast.Pattern {
+	elements := []ast.Pattern{}
+	if p.peekTokenIs(end) {
+		p.nextToken()
+		return elements
+	}
+
+	p.nextToken()
+	for {
+		elem := p.parseSequencePatternElement()
+		if elem == nil {
+			return nil
+		}
+		elements = append(elements, elem)
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+		// allow a trailing comma => (x,)
+		if p.peekTokenIs(end) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.expectPeek(end) {
+		return nil
+	}
+	if !p.checkStarPatterns(elements) {
+		return nil
+	}
+	return elements
+}
+
+func (p *Parser) checkStarPatterns(elements []ast.Pattern) bool {
+	stars := 0
+	for _, elem := range elements {
+		if _, ok := elem.(*ast.StarPattern); ok {
+			stars++
+		}
+	}
+	if stars > 1 {
+		p.errors = append(p.errors, "multiple starred names in sequence pattern")
+		return false
+	}
+	return true
+}
+
+func (p *Parser) parseParenPattern() ast.Pattern {
+	tok := p.currToken
+
+	// (p) is a grouped pattern, while (), (p,) and (p, q) are tuple patterns
+	if !p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		first := p.parseSequencePatternElement()
+		if first == nil {
+			return nil
+		}
+		if _, isStar := first.(*ast.StarPattern); !isStar && p.peekTokenIs(token.RPAREN) {
+			p.nextToken()
+			return first
+		}
+		elements := []ast.Pattern{first}
+		for p.peekTokenIs(token.COMMA) {
+			p.nextToken()
+			if p.peekTokenIs(token.RPAREN) {
+				break
+			}
+			p.nextToken()
+			elem := p.parseSequencePatternElement()
+			if elem == nil {
+				return nil
+			}
+			elements = append(elements, elem)
+		}
+		if !p.expectPeek(token.RPAREN) {
+			return nil
+		}
+		if !p.checkStarPatterns(elements) {
+			return nil
+		}
+		return &ast.SequencePattern{Token: tok, Elements: elements}
+	}
+
+	p.nextToken()
+	return &ast.SequencePattern{Token: tok, Elements: []ast.Pattern{}}
+}
+
+func (p *Parser) parseHashPattern() ast.Pattern {
+	pattern := &ast.HashPattern{Token: p.currToken}
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		key := p.parseExpression(LOWEST)
+		if key == nil {
+			return nil
+		}
+		if !p.expectPeek(token.COLON) {
+			return nil
+		}
+		p.nextToken()
+		value := p.parsePattern()
+		if value == nil {
+			return nil
+		}
+		pattern.Keys = append(pattern.Keys, key)
+		pattern.Values = append(pattern.Values, value)
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+	return pattern
+}
+
+func (p *Parser) parseClassPattern(name *ast.Identifier) ast.Pattern {
+	pattern := &ast.ClassPattern{Token: name.Token, Type: name}
+
+	pattern.Arguments = []ast.Pattern{}
+	if p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		return pattern
+	}
+
+	p.nextToken()
+	arg := p.parsePattern()
+	if arg == nil {
+		return nil
+	}
+	pattern.Arguments = append(pattern.Arguments, arg)
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		p.nextToken()
+		arg := p.parsePattern()
+		if arg == nil {
+			return nil
+		}
+		pattern.Arguments = append(pattern.Arguments, arg)
+	}
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+	return pattern
+}
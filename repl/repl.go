@@ -1,6 +1,7 @@
 package repl
 
 import (
+	"flag"
 	"fmt"
 	"io"
 	"os"
@@ -46,10 +47,16 @@ const ODINS_EYE = `
 
   `
 
-func Start(in io.Reader, out io.Writer) {
+// Start runs the file named on the command line, or reads and evaluates
+// lines from in until exit. assertions controls whether assert statements
+// are checked.
+func Start(in io.Reader, out io.Writer, assertions bool) {
 	line := liner.NewLiner()
 	defer line.Close()
-	env := object.NewEnvironment()
+	interp := evaluator.NewInterpreter(in, out, os.Stderr)
+	interp.Args = flag.Args()
+	interp.AssertionsEnabled = assertions
+	env := interp.NewEnvironment()
 
 	// Optional: Set a custom tab completion function
 	// line.SetCompleter(func(line string) []string {
@@ -62,8 +69,8 @@ func Start(in io.Reader, out io.Writer) {
 	// 	line.ReadHistory(f)
 	// 	f.Close()
 	// }
-	if len(os.Args) > 1 {
-		filePath := os.Args[1]
+	if flag.NArg() > 0 {
+		filePath := flag.Arg(0)
 		if strings.HasSuffix(filePath, ".crl") {
 			err := ProcessFile(filePath, out, env)
 			if err != nil {
@@ -244,7 +251,13 @@ func clearScreen(out io.Writer) {
 	fmt.Fprint(out, "\033[H\033[2J")
 }
 
+// ProcessFile runs a Carrion source file in env. An env without an
+// interpreter gets one that prints to out.
 func ProcessFile(filePath string, out io.Writer, env *object.Environment) error {
+	if env.Host() == nil {
+		env.Global().SetHost(evaluator.NewInterpreter(os.Stdin, out, os.Stderr))
+	}
+
 	content, err := os.ReadFile(filePath)
 	if err != nil {
 		return fmt.Errorf("error reading file %s: %w", filePath, err)
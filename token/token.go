@@ -28,10 +28,19 @@ const (
 	ASTERISK        TokenType = "*"
 	SLASH           TokenType = "/"
 	MOD             TokenType = "%"
+	POWER           TokenType = "**"
+	FLOOR_DIV       TokenType = "//"
 	INCREMENT       TokenType = "+="
 	DECREMENT       TokenType = "-="
 	MULTASSGN       TokenType = "*="
 	DIVASSGN        TokenType = "/="
+	POWASSGN        TokenType = "**="
+	FLOORDIVASSGN   TokenType = "//="
+	ANDASSGN        TokenType = "&="
+	ORASSGN         TokenType = "|="
+	XORASSGN        TokenType = "^="
+	LSHIFTASSGN     TokenType = "<<="
+	RSHIFTASSGN     TokenType = ">>="
 	PLUS_INCREMENT  TokenType = "++"
 	MINUS_DECREMENT TokenType = "--"
 	EQ              TokenType = "=="
@@ -42,8 +51,13 @@ const (
 	GE              TokenType = ">="
 	BANG            TokenType = "!"
 	AMPERSAND       TokenType = "&"
+	CARET           TokenType = "^"
+	TILDE           TokenType = "~"
+	LSHIFT          TokenType = "<<"
+	RSHIFT          TokenType = ">>"
 	HASH            TokenType = "#"
 	AT              TokenType = "@"
+	PIPELINE        TokenType = "|>"
 
 	// Delimiters
 	COMMA     TokenType = ","
@@ -61,6 +75,9 @@ const (
 
 	// Keywords
 	VAR       TokenType = "VAR"
+	CONST     TokenType = "CONST"
+	GLOBAL    TokenType = "GLOBAL"
+	NONLOCAL  TokenType = "NONLOCAL"
 	SPELL     TokenType = "SPELL"
 	SPELLBOOK TokenType = "SPELLBOOK"
 	TRUE      TokenType = "TRUE"
@@ -74,17 +91,28 @@ const (
 	STOP      TokenType = "STOP"
 	SKIP      TokenType = "SKIP"
 	IGNORE    TokenType = "IGNORE"
+	ASSERT    TokenType = "ASSERT"
+	WITH      TokenType = "WITH"
+	AS        TokenType = "AS"
+	IMPORT    TokenType = "IMPORT"
 	RETURN    TokenType = "RETURN"
+	YIELD     TokenType = "YIELD"
 	RANGE     TokenType = "RANGE"
 	NONE      TokenType = "NONE"
+	MATCH     TokenType = "MATCH"
+	CASE      TokenType = "CASE"
 	// Logical Operators
 	AND TokenType = "AND"
 	OR  TokenType = "OR"
 	NOT TokenType = "NOT"
+	IS  TokenType = "IS"
 )
 
 var keywords = map[string]TokenType{
 	"var":       VAR,
+	"const":     CONST,
+	"global":    GLOBAL,
+	"nonlocal":  NONLOCAL,
 	"spell":     SPELL,
 	"spellbook": SPELLBOOK,
 	"True":      TRUE,
@@ -98,12 +126,20 @@ var keywords = map[string]TokenType{
 	"stop":      STOP,
 	"skip":      SKIP,
 	"ignore":    IGNORE,
+	"assert":    ASSERT,
+	"with":      WITH,
+	"as":        AS,
+	"import":    IMPORT,
 	"and":       AND,
 	"or":        OR,
 	"not":       NOT,
+	"is":        IS,
 	"return":    RETURN,
+	"yield":     YIELD,
 	"range":     RANGE,
 	"none":      NONE,
+	"match":     MATCH,
+	"case":      CASE,
 }
 
 func LookupIdent(ident string) TokenType {